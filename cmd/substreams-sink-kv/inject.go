@@ -12,6 +12,7 @@ import (
 	"github.com/streamingfast/shutter"
 	sink "github.com/streamingfast/substreams-sink"
 	"github.com/streamingfast/substreams-sink-kv/db"
+	"github.com/streamingfast/substreams-sink-kv/server/standard"
 	"github.com/streamingfast/substreams-sink-kv/sinker"
 	"go.uber.org/zap"
 )
@@ -28,7 +29,17 @@ var injectCmd = Command(injectRunE,
 		flags.String("server-listen-addr", "", "Launch query server on this address")
 		flags.Bool("server-listen-ssl-self-signed", false, "Listen with an HTTPS server (with self-signed certificate)")
 		flags.String("server-api-prefix", "", "Launch query server with this API prefix so the URl to query is <server-listen-addr>/<server-api-prefix>")
+		flags.Duration("server-min-block-timeout", standard.DefaultMinBlockTimeout, "How long a read with 'min_block' waits for the block to be flushed before failing with FAILED_PRECONDITION")
+		addAuthFlags(flags, "server-")
+		addRateLimitFlags(flags, "server-")
+		flags.Int("server-read-cache-size", 0, "Number of keys whose latest value read through Get and GetMany is kept in memory by the query server, each flush invalidating the keys it writes, 0 disables the cache")
 		flags.Int("query-rows-limit", 5000, "Query rows limit when fetching from database if user specify an unlimited scan or if his limit is above this value")
+		flags.Int("query-keys-limit", 100000, "Query keys limit of the 'keys_only' Scan and GetByPrefix queries and of Count, which do not transfer values and can go higher than --query-rows-limit")
+		flags.Bool("versioned-keys", false, "Also store every SET and DELETE along its block number so that keys can be read at a past block through 'at_block'")
+		flags.Uint64("versions-retention", 0, "With --versioned-keys, number of blocks below the final block for which past values are kept, 0 keeps them all")
+		flags.Bool("change-log", false, "Also append every change applied by a flush, reorg reversions included, to an ordered change log read through 'ReadChanges'")
+		flags.Uint64("change-log-retention-entries", 0, "With --change-log, number of most recent entries kept, 0 keeps them all")
+		flags.Uint64("change-log-retention-blocks", 0, "With --change-log, number of blocks below the flushed block for which entries are kept, 0 keeps them all")
 
 		flags.String("listen-addr", "", "Launch query server on this address")
 		flags.Lookup("listen-addr").Deprecated = "use --server-listen-addr instead"
@@ -67,6 +78,8 @@ func injectRunE(cmd *cobra.Command, args []string) error {
 
 	sink.RegisterMetrics()
 	sinker.RegisterMetrics()
+	standard.RegisterMetrics()
+	db.RegisterMetrics()
 
 	endpoint, dsn, manifestPath, blockRange := extractInjectArgs(cmd, args)
 	queryRowLimit := sflags.MustGetInt(cmd, "query-rows-limit")
@@ -103,9 +116,15 @@ func injectRunE(cmd *cobra.Command, args []string) error {
 
 	zlog.Info("starting KV sinker", fields...)
 
-	kvDB, err := db.New(dsn, queryRowLimit, zlog, tracer)
-	if err != nil {
-		return fmt.Errorf("new psql loader: %w", err)
+	dbOptions := []db.Option{db.WithQueryKeysLimit(sflags.MustGetInt(cmd, "query-keys-limit"))}
+	if sflags.MustGetBool(cmd, "versioned-keys") {
+		dbOptions = append(dbOptions, db.WithVersionedKeys(sflags.MustGetUint64(cmd, "versions-retention")))
+	}
+	if sflags.MustGetBool(cmd, "change-log") {
+		dbOptions = append(dbOptions, db.WithChangeLog(sflags.MustGetUint64(cmd, "change-log-retention-entries"), sflags.MustGetUint64(cmd, "change-log-retention-blocks")))
+	}
+	if size := sflags.MustGetInt(cmd, "server-read-cache-size"); size > 0 && listenAddr != "" {
+		dbOptions = append(dbOptions, db.WithReadCache(size))
 	}
 
 	outputModuleName := sink.InferOutputModuleFromPackage
@@ -123,6 +142,19 @@ func injectRunE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unable to setup sinker: %w", err)
 	}
 
+	indexes, err := sinkConfigIndexes(sink.Package())
+	if err != nil {
+		return fmt.Errorf("sink config indexes: %w", err)
+	}
+	if len(indexes) > 0 {
+		dbOptions = append(dbOptions, db.WithIndexes(indexes...))
+	}
+
+	kvDB, err := db.New(dsn, queryRowLimit, zlog, tracer, dbOptions...)
+	if err != nil {
+		return fmt.Errorf("new psql loader: %w", err)
+	}
+
 	kvSinker, err := sinker.New(sink, kvDB, flushInterval, zlog, tracer)
 	if err != nil {
 		return fmt.Errorf("unable to setup sinker: %w", err)
@@ -153,11 +185,31 @@ func injectRunE(cmd *cobra.Command, args []string) error {
 
 	if listenAddr != "" {
 		zlog.Info("setting up query server")
-		server, err := setupServer(cmd, sink.Package(), kvDB, apiPrefix, listenSslSelfSigned)
+		serverOptions := []standard.Option{standard.WithMinBlockTimeout(sflags.MustGetDuration(cmd, "server-min-block-timeout"))}
+		authenticator, err := newAuthenticator(cmd, "server-")
+		if err != nil {
+			return err
+		}
+		if authenticator != nil {
+			serverOptions = append(serverOptions, standard.WithAuthenticator(authenticator))
+		}
+		limiter := newRateLimiter(cmd, "server-")
+		if limiter != nil {
+			serverOptions = append(serverOptions, standard.WithRateLimiter(limiter), standard.WithQueryLimits(kvDB.QueryRowsLimit, kvDB.QueryKeysLimit))
+		}
+
+		server, err := setupServer(cmd, sink.Package(), kvDB, kvDB, apiPrefix, listenSslSelfSigned, serverOptions...)
 		if err != nil {
 			return fmt.Errorf("setup server: %w", err)
 
 		}
+		_, isStandard := server.(*standard.ConnectServer)
+		if authenticator != nil && !isStandard {
+			return fmt.Errorf("authentication is only available for the GenericService and IndexedService sink configs")
+		}
+		if limiter != nil && !isStandard {
+			return fmt.Errorf("rate limiting is only available for the GenericService and IndexedService sink configs")
+		}
 		app.OnTerminating(func(_ error) {
 			zlog.Info("inject terminating shutting down server")
 			server.Shutdown()
@@ -12,12 +12,16 @@ import (
 	"github.com/streamingfast/derr"
 	"github.com/streamingfast/shutter"
 	"github.com/streamingfast/substreams-sink-kv/db"
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/streamingfast/substreams-sink-kv/protofiles"
 	"github.com/streamingfast/substreams-sink-kv/server"
+	"github.com/streamingfast/substreams-sink-kv/server/auth"
+	"github.com/streamingfast/substreams-sink-kv/server/ratelimit"
 	"github.com/streamingfast/substreams-sink-kv/server/standard"
+	"github.com/streamingfast/substreams-sink-kv/server/wasm"
 	"github.com/streamingfast/substreams/manifest"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 	"go.uber.org/zap"
-	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 var serveCmd = Command(serveRunE,
@@ -29,6 +33,12 @@ var serveCmd = Command(serveRunE,
 		flags.Bool("listen-ssl-self-signed", false, "Listen with an HTTPS server (with self-signed certificate)")
 		flags.String("api-prefix", "", "Launch query server with this API prefix so the URl to query is <listen-addr>/<api-prefix>")
 		flags.Int("query-rows-limit", 5000, "Query rows limit when fetching from database if user specify an unlimited scan or if his limit is above this value")
+		flags.Int("query-keys-limit", 100000, "Query keys limit of the 'keys_only' Scan and GetByPrefix queries and of Count, which do not transfer values and can go higher than --query-rows-limit")
+		flags.String("rest-listen-addr", "", "Also serve the GenericService and IndexedService read RPCs as plain HTTP routes (/v1/kv/{key}, /v1/prefix/{prefix}, /v1/scan and /v1/count) on this address")
+		flags.Duration("min-block-timeout", standard.DefaultMinBlockTimeout, "How long a read with 'min_block' waits for the block to be committed before failing with FAILED_PRECONDITION")
+		addAuthFlags(flags, "")
+		addRateLimitFlags(flags, "")
+		flags.String("rest-value-encoding", "base64", "Encoding of the values returned by the REST routes, one of 'base64', 'hex' or 'raw' (values that are not valid UTF-8 falling back to 'base64'), overridden per request with the 'encoding' query parameter")
 	}),
 	Description(`
 		Launches a query server connected to a key-value store
@@ -42,6 +52,8 @@ var serveCmd = Command(serveRunE,
 func serveRunE(cmd *cobra.Command, args []string) error {
 	app := shutter.New()
 
+	standard.RegisterMetrics()
+
 	ctx, cancelApp := context.WithCancel(cmd.Context())
 	app.OnTerminating(func(_ error) {
 		cancelApp()
@@ -54,6 +66,11 @@ func serveRunE(cmd *cobra.Command, args []string) error {
 	listenSslSelfSigned := sflags.MustGetBool(cmd, "listen-ssl-self-signed")
 	apiPrefix := sflags.MustGetString(cmd, "api-prefix")
 	queryRowLimit := sflags.MustGetInt(cmd, "query-rows-limit")
+	restListenAddr := sflags.MustGetString(cmd, "rest-listen-addr")
+	restValueEncoding, err := standard.ParseValueEncoding(sflags.MustGetString(cmd, "rest-value-encoding"))
+	if err != nil {
+		return fmt.Errorf("invalid --rest-value-encoding: %w", err)
+	}
 
 	zlog.Info("serve substreams-sink-kv",
 		zap.String("dsn", dsn),
@@ -61,6 +78,7 @@ func serveRunE(cmd *cobra.Command, args []string) error {
 		zap.String("listen_addr", listenAddr),
 		zap.Bool("listen_ssl_self_signed", listenSslSelfSigned),
 		zap.String("api_prefix", apiPrefix),
+		zap.String("rest_listen_addr", restListenAddr),
 	)
 
 	manifestReader, err := manifest.NewReader(manifestPath)
@@ -73,7 +91,16 @@ func serveRunE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("read manifest %q: %w", manifestPath, err)
 	}
 
-	kvDB, err := db.New(dsn, queryRowLimit, zlog, tracer)
+	dbOptions := []db.Option{db.WithQueryKeysLimit(sflags.MustGetInt(cmd, "query-keys-limit"))}
+	indexes, err := sinkConfigIndexes(pkg)
+	if err != nil {
+		return fmt.Errorf("sink config indexes: %w", err)
+	}
+	if len(indexes) > 0 {
+		dbOptions = append(dbOptions, db.WithIndexes(indexes...))
+	}
+
+	kvDB, err := db.New(dsn, queryRowLimit, zlog, tracer, dbOptions...)
 	if err != nil {
 		return fmt.Errorf("new kvdb: %w", err)
 	}
@@ -82,11 +109,34 @@ func serveRunE(cmd *cobra.Command, args []string) error {
 		zap.String("dsn", dsn),
 		zap.String("listen_addr", listenAddr),
 	)
-	server, err := setupServer(cmd, pkg, kvDB, apiPrefix, listenSslSelfSigned)
+	serverOptions := []standard.Option{standard.WithMinBlockTimeout(sflags.MustGetDuration(cmd, "min-block-timeout"))}
+	authenticator, err := newAuthenticator(cmd, "")
+	if err != nil {
+		return err
+	}
+	if authenticator != nil {
+		serverOptions = append(serverOptions, standard.WithAuthenticator(authenticator))
+	}
+	limiter := newRateLimiter(cmd, "")
+	if limiter != nil {
+		serverOptions = append(serverOptions, standard.WithRateLimiter(limiter), standard.WithQueryLimits(kvDB.QueryRowsLimit, kvDB.QueryKeysLimit))
+	}
+
+	server, err := setupServer(cmd, pkg, kvDB, nil, apiPrefix, listenSslSelfSigned, serverOptions...)
 	if err != nil {
 		return fmt.Errorf("setup server: %w", err)
 
 	}
+	connectServer, isStandard := server.(*standard.ConnectServer)
+	if authenticator != nil && !isStandard {
+		return fmt.Errorf("authentication is only available for the GenericService and IndexedService sink configs")
+	}
+	if limiter != nil && !isStandard {
+		return fmt.Errorf("rate limiting is only available for the GenericService and IndexedService sink configs")
+	}
+	if restListenAddr != "" && !isStandard {
+		return fmt.Errorf("the REST gateway is only available for the GenericService and IndexedService sink configs")
+	}
 	app.OnTerminating(func(_ error) {
 		zlog.Info("application terminating shutting down server")
 		server.Shutdown()
@@ -98,6 +148,20 @@ func serveRunE(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	if restListenAddr != "" {
+		zlog.Info("setting up rest gateway", zap.String("listen_addr", restListenAddr), zap.String("value_encoding", string(restValueEncoding)))
+		gateway := standard.NewRESTGateway(connectServer, restValueEncoding, zlog)
+		app.OnTerminating(func(_ error) {
+			gateway.Shutdown()
+		})
+
+		go func() {
+			if err := gateway.Serve(restListenAddr); err != nil {
+				app.Shutdown(err)
+			}
+		}()
+	}
+
 	// Clean up and wait
 	signalHandler := derr.SetupSignalHandler(0 * time.Second)
 	zlog.Info("ready, waiting for signal to quit")
@@ -120,22 +184,152 @@ func serveRunE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func setupServer(cmd *cobra.Command, pkg *pbsubstreams.Package, kvDB *db.OperationDB, apiPrefix string, listenSslSelfSigned bool) (server.Serveable, error) {
+// setupServer creates the query server of the spkg sink config, watcher is nil when
+// the server does not run along the injector.
+func setupServer(cmd *cobra.Command, pkg *pbsubstreams.Package, kvDB *db.OperationDB, watcher db.Watcher, apiPrefix string, listenSslSelfSigned bool, opts ...standard.Option) (server.Serveable, error) {
 	if pkg.SinkConfig == nil {
 		return nil, fmt.Errorf("no sink config found in spkg")
 	}
-	return standard.NewServer(kvDB, zlog, listenSslSelfSigned), nil
+
+	switch pkg.SinkConfig.MessageName() {
+	case "sf.substreams.sink.kv.v1.WASMQueryService":
+		wasmServ := &kvv1.WASMQueryService{}
+		if err := pkg.SinkConfig.UnmarshalTo(wasmServ); err != nil {
+			return nil, fmt.Errorf("unmarshalling sink config: %w", err)
+		}
+
+		zlog.Info("setting up wasm query server", zap.String("grpc_service", wasmServ.GrpcService))
+		return wasm.NewServer(cmd.Context(), wasmServ, pkg.ProtoFiles, kvDB, zlog, listenSslSelfSigned)
+
+	case "sf.substreams.sink.kv.v1.GenericService":
+		genericServ := &kvv1.GenericService{}
+		if err := pkg.SinkConfig.UnmarshalTo(genericServ); err != nil {
+			return nil, fmt.Errorf("unmarshalling sink config: %w", err)
+		}
+
+		valueTypes, err := newValueTypes(genericServ.ValueTypes, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return standard.NewServer(kvDB, watcher, valueTypes, zlog, listenSslSelfSigned, opts...), nil
+
+	case "sf.substreams.sink.kv.v1.IndexedService":
+		indexedServ := &kvv1.IndexedService{}
+		if err := pkg.SinkConfig.UnmarshalTo(indexedServ); err != nil {
+			return nil, fmt.Errorf("unmarshalling sink config: %w", err)
+		}
+
+		valueTypes, err := newValueTypes(indexedServ.ValueTypes, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return standard.NewServer(kvDB, watcher, valueTypes, zlog, listenSslSelfSigned, opts...), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported sink config type %q", pkg.SinkConfig.TypeUrl)
+	}
 }
 
-func findProtoDefWithGRPCService(pkg *pbsubstreams.Package, fqGrpcService string) (*descriptorpb.FileDescriptorProto, error) {
-	for _, f := range pkg.ProtoFiles {
-		for _, srv := range f.Service {
-			servName := fmt.Sprintf("%s.%s", f.GetPackage(), srv.GetName())
-			if servName == fqGrpcService {
-				return f, nil
-			}
+// addAuthFlags adds the authentication flags of the query server, their names starting
+// with prefix.
+func addAuthFlags(flags *pflag.FlagSet, prefix string) {
+	flags.String(prefix+"auth-api-keys-file", "", "JSON file of the API keys accepted by the query server as 'X-Api-Key' or 'Authorization: Bearer' headers, each with the key prefixes it may read, e.g. {\"keys\": [{\"name\": \"explorer\", \"key\": \"<secret>\", \"prefixes\": [\"account:\"]}]}")
+	flags.String(prefix+"auth-jwks-file", "", "JWKS file of the keys verifying the JWTs accepted by the query server as 'Authorization: Bearer' headers")
+	flags.String(prefix+"auth-jwt-issuer", "", "With --"+prefix+"auth-jwks-file, the 'iss' claim required from the JWTs, any issuer being accepted when empty")
+	flags.String(prefix+"auth-jwt-audience", "", "With --"+prefix+"auth-jwks-file, the audience required in the 'aud' claim of the JWTs, any audience being accepted when empty")
+	flags.String(prefix+"auth-jwt-prefixes-claim", auth.DefaultPrefixesClaim, "With --"+prefix+"auth-jwks-file, the JWT claim listing the key prefixes the token may read")
+}
+
+// newAuthenticator returns the authenticator configured by the flags added by
+// addAuthFlags, nil when the query server is not authenticated.
+func newAuthenticator(cmd *cobra.Command, prefix string) (auth.Authenticator, error) {
+	var authenticators auth.Authenticators
+	if path := sflags.MustGetString(cmd, prefix+"auth-api-keys-file"); path != "" {
+		keys, err := auth.LoadAPIKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, keys)
+	}
+	if path := sflags.MustGetString(cmd, prefix+"auth-jwks-file"); path != "" {
+		jwt, err := auth.LoadJWT(path, sflags.MustGetString(cmd, prefix+"auth-jwt-issuer"), sflags.MustGetString(cmd, prefix+"auth-jwt-audience"))
+		if err != nil {
+			return nil, err
 		}
+		jwt.PrefixesClaim = sflags.MustGetString(cmd, prefix+"auth-jwt-prefixes-claim")
+		authenticators = append(authenticators, jwt)
+	}
+	if len(authenticators) == 0 {
+		return nil, nil
+	}
+	return authenticators, nil
+}
+
+// addRateLimitFlags adds the rate limiting flags of the query server, their names
+// starting with prefix.
+func addRateLimitFlags(flags *pflag.FlagSet, prefix string) {
+	flags.Float64(prefix+"rate-limit", 0, "Tokens per second granted to each client of the query server, identified by its API key or JWT subject or else by its remote IP, a request costing one token plus one per row or key returned beyond the first (one per requested key for GetMany), the rows up to the request limit being reserved before reading, 0 disables rate limiting")
+	flags.Int(prefix+"rate-limit-burst", 5000, "With --"+prefix+"rate-limit, tokens a client can spend at once, a request costing more requiring a full budget")
+}
+
+// newRateLimiter returns the rate limiter configured by the flags added by
+// addRateLimitFlags, nil when the query server is not rate limited.
+func newRateLimiter(cmd *cobra.Command, prefix string) *ratelimit.Limiter {
+	rate := sflags.MustGetFloat64(cmd, prefix+"rate-limit")
+	if rate <= 0 {
+		return nil
+	}
+
+	return ratelimit.New(rate, sflags.MustGetInt(cmd, prefix+"rate-limit-burst"))
+}
+
+func newValueTypes(configs []*kvv1.ValueType, pkg *pbsubstreams.Package) (*standard.ValueTypes, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	valueTypes, err := standard.NewValueTypes(configs, pkg.ProtoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("sink config value types: %w", err)
+	}
+	return valueTypes, nil
+}
+
+// sinkConfigIndexes returns the secondary indexes declared by an IndexedService sink
+// config, their value types being resolved from the spkg proto files.
+func sinkConfigIndexes(pkg *pbsubstreams.Package) ([]*db.Index, error) {
+	if pkg.SinkConfig == nil || pkg.SinkConfig.MessageName() != "sf.substreams.sink.kv.v1.IndexedService" {
+		return nil, nil
+	}
+
+	indexedServ := &kvv1.IndexedService{}
+	if err := pkg.SinkConfig.UnmarshalTo(indexedServ); err != nil {
+		return nil, fmt.Errorf("unmarshalling sink config: %w", err)
+	}
+
+	files, err := protofiles.New(pkg.ProtoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("building spkg proto files: %w", err)
 	}
 
-	return nil, fmt.Errorf("unable to find proto file descriptor with pakage %q in spkg", fqGrpcService)
+	names := map[string]bool{}
+	var indexes []*db.Index
+	for _, config := range indexedServ.Indexes {
+		if names[config.Name] {
+			return nil, fmt.Errorf("index %q is declared twice", config.Name)
+		}
+		names[config.Name] = true
+
+		valueType, err := protofiles.FindMessage(files, config.ValueType)
+		if err != nil {
+			return nil, fmt.Errorf("index %q: %w", config.Name, err)
+		}
+
+		index, err := db.NewIndex(config.Name, config.KeyPrefix, valueType, config.Field)
+		if err != nil {
+			return nil, fmt.Errorf("index %q: %w", config.Name, err)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
 }
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+// applyAppendOperation computes the new value of key once the APPEND operation is
+// applied to current, a missing value counting as empty.
+func applyAppendOperation(op *pbkv.KVOperation, current []byte) ([]byte, error) {
+	switch op.AppendEncoding {
+	case pbkv.KVOperation_RAW:
+		out := make([]byte, 0, len(current)+len(op.Value))
+		out = append(out, current...)
+		return append(out, op.Value...), nil
+	case pbkv.KVOperation_LENGTH_PREFIXED:
+		if len(op.Value) > math.MaxUint32 {
+			return nil, fmt.Errorf("element of %d bytes is too large to be length prefixed", len(op.Value))
+		}
+		out := make([]byte, 0, len(current)+4+len(op.Value))
+		out = append(out, current...)
+		out = binary.BigEndian.AppendUint32(out, uint32(len(op.Value)))
+		return append(out, op.Value...), nil
+	default:
+		return nil, fmt.Errorf("invalid append encoding %s", op.AppendEncoding)
+	}
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"container/list"
+	"sync"
+)
+
+// readCache is a bounded LRU of the latest values of keys read through Get and GetMany,
+// missing keys included. It is only correct when the flushes go through this instance,
+// each flush invalidating the keys it writes, the keys reverted by HandleBlockUndo
+// included.
+type readCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	// generation is incremented by each invalidation, a value read from the store being
+	// only cached when no flush happened since the read started, see put
+	generation uint64
+}
+
+type readCacheEntry struct {
+	key   string
+	value []byte
+	found bool
+}
+
+func newReadCache(size int) *readCache {
+	return &readCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// get returns the cached value of key, cached being false when it must be read from the
+// store, found being false when the key is known to be missing.
+func (c *readCache) get(key string) (value []byte, found bool, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, cached := c.entries[key]
+	if !cached {
+		ReadCacheMisses.Inc()
+		return nil, false, false
+	}
+	ReadCacheHits.Inc()
+	c.lru.MoveToFront(element)
+	entry := element.Value.(*readCacheEntry)
+	return entry.value, entry.found, true
+}
+
+// currentGeneration is read before reading from the store the values passed to put.
+func (c *readCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// put caches the value of key read from the store, unless a flush invalidated keys since
+// generation was read, the value being then possibly stale.
+func (c *readCache) put(generation uint64, key string, value []byte, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if element, exists := c.entries[key]; exists {
+		element.Value = &readCacheEntry{key: key, value: value, found: found}
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&readCacheEntry{key: key, value: value, found: found})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*readCacheEntry).key)
+	}
+}
+
+// invalidate drops the user keys written by batch, called once the batch is committed.
+func (c *readCache) invalidate(batch *flushBatch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	drop := func(key []byte) {
+		if len(key) == 0 || key[0] != userKeyPrefix {
+			return
+		}
+		if element, exists := c.entries[fromUserKey(key)]; exists {
+			c.lru.Remove(element)
+			delete(c.entries, fromUserKey(key))
+		}
+	}
+	for _, key := range batch.deletes {
+		drop(key)
+	}
+	for _, kv := range batch.puts {
+		drop(kv.Key)
+	}
+}
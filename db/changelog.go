@@ -0,0 +1,310 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/kvdb/store"
+	sink "github.com/streamingfast/substreams-sink"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// The change log lives under the `xl` keyspace:
+//
+//   - `xle` + BE(sequence) holds the protobuf encoded ChangeLogEntry of each change
+//     applied by a flush, in the order they were applied
+//   - `xln` holds the sequence of the next entry
+//   - `xlf` holds the oldest sequence that was not purged
+//
+// Entries are written by the same commit as the changes they describe, so the change
+// log is always in sync with the user keys.
+var changeLogEntryPrefix = []byte("xle")
+var changeLogNextKey = []byte("xln")
+var changeLogFirstKey = []byte("xlf")
+
+var ErrChangesPurged = errors.New("change log entries were purged")
+
+type changeLog struct {
+	retentionEntries uint64
+	retentionBlocks  uint64
+
+	// next and first mirror the committed `xln` and `xlf` values once loaded
+	loaded bool
+	next   uint64
+	first  uint64
+
+	// pending are the operations added since the last flush, in the order they were
+	// added, block being the one handled by HandleOperations, see startRecording
+	pending []*changeLogOperation
+	block   bstream.BlockRef
+}
+
+// changeLogOperation is an operation added since the last flush along the block it
+// belongs to, a nil block standing for the block of the flush cursor.
+type changeLogOperation struct {
+	op    *pbkv.KVOperation
+	block bstream.BlockRef
+	undo  bool
+}
+
+func newChangeLog(retentionEntries, retentionBlocks uint64) *changeLog {
+	return &changeLog{
+		retentionEntries: retentionEntries,
+		retentionBlocks:  retentionBlocks,
+	}
+}
+
+func (c *changeLog) startRecording(block bstream.BlockRef) {
+	c.block = block
+}
+
+func (c *changeLog) stopRecording() {
+	c.block = nil
+}
+
+// record keeps op, a SET, a DELETE or a DELETE_PREFIX, for the next flush. Operations
+// added outside of HandleOperations are undo operations when undo is set.
+func (c *changeLog) record(op *pbkv.KVOperation, undo bool) {
+	c.pending = append(c.pending, &changeLogOperation{op: op, block: c.block, undo: c.block == nil && undo})
+}
+
+func (c *changeLog) reset() {
+	c.pending = nil
+}
+
+func (c *changeLog) load(ctx context.Context, s store.KVStore) (err error) {
+	if c.loaded {
+		return nil
+	}
+	if c.next, err = readSequence(ctx, s, changeLogNextKey); err != nil {
+		return fmt.Errorf("reading next sequence: %w", err)
+	}
+	if c.first, err = readSequence(ctx, s, changeLogFirstKey); err != nil {
+		return fmt.Errorf("reading first sequence: %w", err)
+	}
+	c.loaded = true
+	return nil
+}
+
+// flushChangeLog adds an entry for each change of the operations added since the last
+// flush and purges the entries that fell out of the retention window. Entries follow
+// the order the operations were added, so the ordinal order within a block, each one
+// holding the block of its operation. The returned sequences are the ones to keep in
+// memory once the batch is committed.
+func (db *OperationDB) flushChangeLog(ctx context.Context, batch *flushBatch, cursor *sink.Cursor) (next, first uint64, err error) {
+	if err := db.changeLog.load(ctx, db.store); err != nil {
+		return 0, 0, err
+	}
+	next, first = db.changeLog.next, db.changeLog.first
+	blockNum := cursor.Block().Num()
+
+	// values are the values of the keys changed so far by the flush, a nil value being
+	// a deleted key
+	values := map[string][]byte{}
+	current := func(key string) ([]byte, error) {
+		if value, found := values[key]; found {
+			return value, nil
+		}
+		value, err := db.store.Get(ctx, userKey(key))
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("getting previous value of key %q: %w", key, err)
+		}
+		return value, nil
+	}
+
+	addEntry := func(pending *changeLogOperation, changeType pbkv.Change_Type, key string, newValue []byte) error {
+		block := pending.block
+		if block == nil {
+			block = cursor.Block()
+		}
+		oldValue, err := current(key)
+		if err != nil {
+			return err
+		}
+		entry := &pbkv.ChangeLogEntry{
+			Sequence:    next,
+			BlockNumber: block.Num(),
+			BlockId:     block.ID(),
+			Type:        changeType,
+			Key:         key,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+			Undo:        pending.undo,
+		}
+
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("marshalling change log entry: %w", err)
+		}
+		batch.Put(changeLogKey(next), data)
+		next++
+		values[key] = newValue
+		return nil
+	}
+
+	for _, pending := range db.changeLog.pending {
+		op := pending.op
+		switch op.Type {
+		case pbkv.KVOperation_SET:
+			// an empty value is still a value, unlike the nil of a deleted key
+			err = addEntry(pending, pbkv.Change_SET, op.Key, append([]byte{}, op.Value...))
+		case pbkv.KVOperation_DELETE:
+			err = addEntry(pending, pbkv.Change_DELETE, op.Key, nil)
+		case pbkv.KVOperation_DELETE_PREFIX:
+			err = db.changeLogPrefixDeletion(ctx, op.Key, values, func(key string) error {
+				return addEntry(pending, pbkv.Change_DELETE, key, nil)
+			})
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	purgeUntil := first
+	if retention := db.changeLog.retentionEntries; retention > 0 && next-first > retention {
+		purgeUntil = next - retention
+	}
+	if retention := db.changeLog.retentionBlocks; retention > 0 && blockNum > retention {
+		// entries of the current flush are never below the cutoff, only stored ones are scanned
+		until, err := db.changeLogBlockCutoff(ctx, purgeUntil, blockNum-retention)
+		if err != nil {
+			return 0, 0, fmt.Errorf("finding entries below block %d: %w", blockNum-retention, err)
+		}
+		if until > purgeUntil {
+			purgeUntil = until
+		}
+	}
+	for sequence := first; sequence < purgeUntil; sequence++ {
+		batch.Delete(changeLogKey(sequence))
+	}
+	first = purgeUntil
+
+	batch.Put(changeLogNextKey, binary.BigEndian.AppendUint64(nil, next))
+	batch.Put(changeLogFirstKey, binary.BigEndian.AppendUint64(nil, first))
+	return next, first, nil
+}
+
+// changeLogPrefixDeletion calls deleted for each key starting with prefix that exists
+// once the values changed so far by the flush are applied, in key order.
+func (db *OperationDB) changeLogPrefixDeletion(ctx context.Context, prefix string, values map[string][]byte, deleted func(key string) error) error {
+	storedKeys, err := db.prefixKeys(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("listing keys of prefix %q: %w", prefix, err)
+	}
+
+	var keys []string
+	for _, key := range storedKeys {
+		if _, found := values[fromUserKey(key)]; !found {
+			keys = append(keys, fromUserKey(key))
+		}
+	}
+	for key, value := range values {
+		if value != nil && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := deleted(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeLogBlockCutoff returns the sequence of the first stored entry, starting at
+// from, whose block is at or above cutoff. Blocks only decrease across entries on
+// undo, so the purge stops at the first entry that must be kept.
+func (db *OperationDB) changeLogBlockCutoff(ctx context.Context, from uint64, cutoff uint64) (uint64, error) {
+	itr := db.store.Scan(ctx, changeLogKey(from), prefixEnd(changeLogEntryPrefix), 0)
+	for itr.Next() {
+		entry := &pbkv.ChangeLogEntry{}
+		if err := proto.Unmarshal(itr.Item().Value, entry); err != nil {
+			return 0, fmt.Errorf("unmarshalling change log entry: %w", err)
+		}
+		if entry.BlockNumber >= cutoff {
+			return entry.Sequence, nil
+		}
+		from = entry.Sequence + 1
+	}
+	if err := itr.Err(); err != nil {
+		return 0, err
+	}
+	return from, nil
+}
+
+// ReadChanges returns the change log entries starting at fromSequence, nextSequence
+// being the one to read from to continue. Reading entries that were purged is an
+// ErrChangesPurged error.
+func (db *OperationDB) ReadChanges(ctx context.Context, fromSequence uint64, limit int) (entries []*pbkv.ChangeLogEntry, nextSequence uint64, limitReached bool, err error) {
+	if limit == 0 {
+		limit = db.QueryRowsLimit
+	}
+	if limit < 0 || limit > db.QueryRowsLimit {
+		return nil, 0, false, fmt.Errorf("%w: request value for 'limit' must be between 1 and %d, but received %d", ErrInvalidArguments, db.QueryRowsLimit, limit)
+	}
+	if fromSequence == 0 {
+		fromSequence = 1
+	}
+
+	first, err := readSequence(ctx, db.store, changeLogFirstKey)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("reading first sequence: %w", err)
+	}
+	if fromSequence < first {
+		return nil, 0, false, fmt.Errorf("%w: request value for 'from_sequence' must be at least %d, the oldest retained sequence, but received %d", ErrChangesPurged, first, fromSequence)
+	}
+
+	nextSequence = fromSequence
+	itr := db.store.Scan(ctx, changeLogKey(fromSequence), prefixEnd(changeLogEntryPrefix), limit+1)
+	for itr.Next() {
+		if len(entries) == limit {
+			limitReached = true
+			break
+		}
+		entry := &pbkv.ChangeLogEntry{}
+		if err := proto.Unmarshal(itr.Item().Value, entry); err != nil {
+			return nil, 0, false, fmt.Errorf("unmarshalling change log entry: %w", err)
+		}
+		entries = append(entries, entry)
+		nextSequence = entry.Sequence + 1
+	}
+	if err := itr.Err(); err != nil {
+		return nil, 0, false, err
+	}
+	return entries, nextSequence, limitReached, nil
+}
+
+// readSequence reads a change log sequence, sequences start at 1.
+func readSequence(ctx context.Context, s store.KVStore, key []byte) (uint64, error) {
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return 1, nil
+		}
+		return 0, err
+	}
+	if len(value) != 8 {
+		return 0, fmt.Errorf("invalid sequence value")
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// sortChanges orders changes by key, pending operations being gathered from a map.
+func sortChanges(changes []*pbkv.KVOperation) {
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+}
+
+func changeLogKey(sequence uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, changeLogEntryPrefix...), sequence)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/streamingfast/kvdb/store"
 	sink "github.com/streamingfast/substreams-sink"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
 	"go.uber.org/zap"
 )
 
@@ -30,11 +31,94 @@ func (db *OperationDB) GetCursor(ctx context.Context) (*sink.Cursor, error) {
 }
 
 func (db *OperationDB) WriteCursor(ctx context.Context, c *sink.Cursor) error {
-	val := cursorToBytes(c)
-	if err := db.store.Put(ctx, cursorKey, val); err != nil {
+	batch := &flushBatch{}
+	batch.Put(cursorKey, cursorToBytes(c))
+	if err := db.commit(ctx, batch); err != nil {
 		return err
 	}
-	return db.store.FlushPuts(ctx)
+	db.setCommittedBlock(committedBlock(c))
+	return nil
+}
+
+// CommittedBlock returns the block of the last committed cursor, nil if none was
+// committed yet. Data read after calling it reflects at least this block. Once this
+// instance has committed a cursor it is known without reading the store, a server
+// running apart from the injector reads it from the store on each call.
+func (db *OperationDB) CommittedBlock(ctx context.Context) (*pbkv.CommittedBlock, error) {
+	if block := db.committedBlock.Load(); block != nil {
+		return block, nil
+	}
+
+	val, err := db.store.Get(ctx, cursorKey)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cursor, err := cursorFromBytes(val)
+	if err != nil {
+		return nil, err
+	}
+	return committedBlock(cursor), nil
+}
+
+// WaitForBlock returns the committed block once it reaches blockNum, waiting for the
+// flushes of this instance until then, or the last committed block along the error of
+// ctx if it is done first.
+func (db *OperationDB) WaitForBlock(ctx context.Context, blockNum uint64) (*pbkv.CommittedBlock, error) {
+	for {
+		// taken before reading the block so that a commit in between is not missed
+		committed := db.blockCommitted()
+
+		block, err := db.CommittedBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil && block.BlockNumber >= blockNum {
+			return block, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return block, ctx.Err()
+		case <-committed:
+		}
+	}
+}
+
+func (db *OperationDB) setCommittedBlock(block *pbkv.CommittedBlock) {
+	db.committedBlock.Store(block)
+
+	db.subscriptionsLock.Lock()
+	defer db.subscriptionsLock.Unlock()
+
+	if db.committed != nil {
+		close(db.committed)
+		db.committed = nil
+	}
+}
+
+// blockCommitted returns a channel closed on the next commit of a cursor.
+func (db *OperationDB) blockCommitted() <-chan struct{} {
+	db.subscriptionsLock.Lock()
+	defer db.subscriptionsLock.Unlock()
+
+	if db.committed == nil {
+		db.committed = make(chan struct{})
+	}
+	return db.committed
+}
+
+func committedBlock(c *sink.Cursor) *pbkv.CommittedBlock {
+	if c.IsBlank() {
+		return nil
+	}
+	block := &pbkv.CommittedBlock{BlockNumber: c.Block().Num(), BlockId: c.Block().ID()}
+	if c.LIB != nil {
+		block.FinalBlockHeight = c.LIB.Num()
+	}
+	return block
 }
 
 func cursorToBytes(c *sink.Cursor) []byte {
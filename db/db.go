@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
 
 	"github.com/streamingfast/bstream"
 	"github.com/streamingfast/kvdb/store"
@@ -22,30 +25,69 @@ type CursorError struct {
 }
 
 var _ Reader = (*OperationDB)(nil)
+var _ Watcher = (*OperationDB)(nil)
 
 type OperationDB struct {
 	store store.KVStore
 
 	QueryRowsLimit    int
+	QueryKeysLimit    int
 	pendingOperations map[string]*pbkv.KVOperation
 	logger            *zap.Logger
 	tracer            logging.Tracer
 	undosOperations   map[uint64][]byte
+
+	// pendingPrefixDeletions are applied before pendingOperations, see AddOperation
+	pendingPrefixDeletions []string
+
+	// versions is nil unless the DB is configured WithVersionedKeys
+	versions *versions
+
+	// changeLog is nil unless the DB is configured WithChangeLog
+	changeLog *changeLog
+
+	// cache is nil unless the DB is configured WithReadCache
+	cache *readCache
+
+	// indexes are the secondary indexes maintained by flushes, see WithIndexes
+	indexes map[string]*Index
+
+	// expiries is true once keys with an expiry were written, see expiriesInUse
+	expiries        bool
+	expiriesChecked bool
+
+	// pendingUndo is true when pending operations revert blocks, see HandleBlockUndo
+	pendingUndo bool
+
+	// undoneBlocks are the blocks reverted by HandleBlockUndo, their undo entries are
+	// deleted by the next flush
+	undoneBlocks []uint64
+
+	// committedBlock is the block of the last cursor committed by this instance, see
+	// CommittedBlock
+	committedBlock atomic.Pointer[pbkv.CommittedBlock]
+	subscriptionsState
 }
 
-func New(dsn string, queryRowsLimit int, logger *zap.Logger, tracer logging.Tracer) (*OperationDB, error) {
+func New(dsn string, queryRowsLimit int, logger *zap.Logger, tracer logging.Tracer, opts ...Option) (*OperationDB, error) {
 	s, err := store.New(dsn)
 	if err != nil {
 		return nil, err
 	}
-	return &OperationDB{
+	db := &OperationDB{
 		QueryRowsLimit:    queryRowsLimit,
+		QueryKeysLimit:    queryRowsLimit,
 		store:             s,
 		logger:            logger,
 		tracer:            tracer,
 		pendingOperations: make(map[string]*pbkv.KVOperation),
 		undosOperations:   make(map[uint64][]byte),
-	}, nil
+		indexes:           make(map[string]*Index),
+	}
+	for _, opt := range opts {
+		opt.apply(db)
+	}
+	return db, nil
 }
 
 func (db *OperationDB) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
@@ -57,6 +99,10 @@ func (db *OperationDB) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 var undoPrefix = [2]byte{'x', 'u'}
 var userKeyPrefix byte = 'k'
 
+// presenceValue is the value of internal keys that only matter by their existence,
+// some backends do not support empty values.
+var presenceValue = []byte{0x01}
+
 var ErrInvalidArguments = errors.New("invalid arguments")
 var ErrNotFound = errors.New("not found")
 
@@ -66,17 +112,111 @@ var InfiniteEndBytes = []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
 	255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255,
 	255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}
 
-func (db *OperationDB) AddOperations(ops *pbkv.KVOperations) {
+func (db *OperationDB) AddOperations(ctx context.Context, ops *pbkv.KVOperations) error {
 	for _, op := range ops.Operations {
-		db.AddOperation(op)
+		if err := db.AddOperation(ctx, op); err != nil {
+			return fmt.Errorf("adding %s operation for key %q: %w", op.Type, op.Key, err)
+		}
 	}
+	return nil
 }
 
-func (db *OperationDB) AddOperation(op *pbkv.KVOperation) {
+func (db *OperationDB) AddOperation(ctx context.Context, op *pbkv.KVOperation) error {
+	switch op.Type {
+	case pbkv.KVOperation_ADD, pbkv.KVOperation_INCREMENT, pbkv.KVOperation_APPEND:
+		// operations depending on the previous value are resolved right away against the
+		// pending or stored value, so many of them on the same key accumulate within a flush
+		current, found, err := db.currentValue(ctx, op.Key)
+		if err != nil {
+			return err
+		}
+
+		var value []byte
+		if op.Type == pbkv.KVOperation_APPEND {
+			value, err = applyAppendOperation(op, current)
+		} else {
+			value, err = applyNumericOperation(op, current, found)
+		}
+		if err != nil {
+			return err
+		}
+
+		op = &pbkv.KVOperation{
+			Type:    pbkv.KVOperation_SET,
+			Key:     op.Key,
+			Value:   value,
+			Ordinal: op.Ordinal,
+			Expiry:  op.Expiry,
+		}
+	}
+
+	if op.Expiry != nil {
+		db.expiries = true
+	}
+
+	if op.Type == pbkv.KVOperation_DELETE_PREFIX {
+		if op.Key == "" {
+			return fmt.Errorf("%w: prefix must not be empty", ErrInvalidArguments)
+		}
+		if db.versions != nil {
+			if err := db.recordPrefixDeletion(ctx, op.Key); err != nil {
+				return err
+			}
+		}
+
+		// pending operations under the prefix are superseded, the ones received afterward
+		// still apply since prefix deletions are flushed before pending operations
+		for key := range db.pendingOperations {
+			if strings.HasPrefix(key, op.Key) {
+				delete(db.pendingOperations, key)
+			}
+		}
+		db.pendingPrefixDeletions = append(db.pendingPrefixDeletions, op.Key)
+		if db.changeLog != nil {
+			db.changeLog.record(op, db.pendingUndo)
+		}
+		return nil
+	}
+
 	//this will only keep the last operation for a given key
 	db.pendingOperations[op.Key] = op
+	if db.versions != nil {
+		db.versions.record(op)
+	}
+	if db.changeLog != nil {
+		db.changeLog.record(op, db.pendingUndo)
+	}
+	return nil
+}
+
+// currentValue returns the value key will have once pending operations are flushed.
+func (db *OperationDB) currentValue(ctx context.Context, key string) (value []byte, found bool, err error) {
+	if op, ok := db.pendingOperations[key]; ok {
+		if op.Type == pbkv.KVOperation_SET {
+			return op.Value, true, nil
+		}
+		return nil, false, nil
+	}
+
+	for _, prefix := range db.pendingPrefixDeletions {
+		if strings.HasPrefix(key, prefix) {
+			return nil, false, nil
+		}
+	}
+
+	value, err = db.store.Get(ctx, userKey(key))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return value, true, nil
 }
-func (db *OperationDB) HandleOperations(ctx context.Context, blockNumber uint64, finalBlockHeight uint64, step bstream.StepType, kvOps *pbkv.KVOperations) error {
+func (db *OperationDB) HandleOperations(ctx context.Context, block bstream.BlockRef, finalBlockHeight uint64, step bstream.StepType, kvOps *pbkv.KVOperations) error {
+	blockNumber := block.Num()
+	kvOps = &pbkv.KVOperations{Operations: sortByOrdinal(kvOps.Operations)}
+
 	if step == bstream.StepNew {
 		err := db.PurgeUndoOperations(ctx, finalBlockHeight)
 		if err != nil {
@@ -94,37 +234,149 @@ func (db *OperationDB) HandleOperations(ctx context.Context, blockNumber uint64,
 		}
 	}
 
-	db.AddOperations(kvOps)
-	return nil
+	if db.versions != nil {
+		if err := db.PurgeVersionBlocks(ctx, finalBlockHeight); err != nil {
+			return fmt.Errorf("deleting LIB version blocks: %w", err)
+		}
+
+		db.versions.startRecording(blockNumber, finalBlockHeight)
+		defer db.versions.stopRecording()
+	}
+	if db.changeLog != nil {
+		db.changeLog.startRecording(block)
+		defer db.changeLog.stopRecording()
+	}
+
+	return db.AddOperations(ctx, kvOps)
+}
+
+// sortByOrdinal returns the operations of a block in the order they must be applied,
+// by ascending ordinal and by arrival order for operations sharing the same ordinal.
+// This gives a well-defined last-writer-wins when modules merge many sources.
+func sortByOrdinal(ops []*pbkv.KVOperation) []*pbkv.KVOperation {
+	sorted := make([]*pbkv.KVOperation, len(ops))
+	copy(sorted, ops)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Ordinal < sorted[j].Ordinal
+	})
+	return sorted
 }
 
+// Flush commits the pending operations, the undo entries and the cursor as a single
+// unit, see `commit` for the details on how atomicity is achieved.
 func (db *OperationDB) Flush(ctx context.Context, cursor *sink.Cursor) (count int, err error) {
+	// changes are only gathered when someone is there to receive them
+	var changes []*pbkv.KVOperation
+	subscribed := db.hasSubscriptions()
+
+	expiries, err := db.expiriesInUse(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := &flushBatch{}
+	for _, prefix := range db.pendingPrefixDeletions {
+		keys, err := db.prefixKeys(ctx, prefix)
+		if err != nil {
+			return 0, fmt.Errorf("listing keys to delete for prefix %q: %w", prefix, err)
+		}
+
+		for _, key := range keys {
+			if _, found := db.pendingOperations[fromUserKey(key)]; found {
+				// superseded by an operation received after the prefix deletion
+				continue
+			}
+			batch.Delete(key)
+			if expiries {
+				if err := db.flushExpiry(ctx, batch, fromUserKey(key), nil); err != nil {
+					return 0, err
+				}
+			}
+			if err := db.flushIndexes(ctx, batch, fromUserKey(key), nil); err != nil {
+				return 0, fmt.Errorf("flushing indexes: %w", err)
+			}
+			if subscribed {
+				changes = append(changes, &pbkv.KVOperation{Type: pbkv.KVOperation_DELETE, Key: fromUserKey(key)})
+			}
+		}
+	}
+
 	for _, op := range db.pendingOperations {
 		switch op.Type {
 		case pbkv.KVOperation_SET:
-			if err := db.store.Put(ctx, userKey(op.Key), op.Value); err != nil {
-				return 0, err
-			}
+			batch.Put(userKey(op.Key), op.Value)
 		case pbkv.KVOperation_DELETE:
-			if err := db.store.BatchDelete(ctx, [][]byte{userKey(op.Key)}); err != nil {
-				return 0, err
-			}
+			batch.Delete(userKey(op.Key))
 		default:
 			panic(fmt.Sprintf("invalid operation type %d", op.Type))
 		}
+		if expiries {
+			if err := db.flushExpiry(ctx, batch, op.Key, op); err != nil {
+				return 0, err
+			}
+		}
+		var newValue []byte
+		if op.Type == pbkv.KVOperation_SET {
+			// an empty value is still a value to index
+			newValue = append([]byte{}, op.Value...)
+		}
+		if err := db.flushIndexes(ctx, batch, op.Key, newValue); err != nil {
+			return 0, fmt.Errorf("flushing indexes: %w", err)
+		}
+		if subscribed {
+			changes = append(changes, op)
+		}
+	}
+	if subscribed {
+		sortChanges(changes)
 	}
 
+	var changeLogNext, changeLogFirst uint64
+	if db.changeLog != nil {
+		// previous values are read before the batch is committed
+		if changeLogNext, changeLogFirst, err = db.flushChangeLog(ctx, batch, cursor); err != nil {
+			return 0, fmt.Errorf("flushing change log: %w", err)
+		}
+	}
+
+	for _, blockNumber := range db.undoneBlocks {
+		if _, found := db.undosOperations[blockNumber]; found {
+			// the block was replayed on the new fork, its entry is overwritten below
+			continue
+		}
+		batch.Delete(undoKey(blockNumber))
+	}
 	for blockNumber, undoOperations := range db.undosOperations {
-		if err := db.store.Put(ctx, undoKey(blockNumber), undoOperations); err != nil {
-			return 0, err
+		batch.Put(undoKey(blockNumber), undoOperations)
+	}
+
+	if db.versions != nil {
+		if err := db.flushVersions(ctx, batch); err != nil {
+			return 0, fmt.Errorf("flushing versions: %w", err)
 		}
 	}
 
-	if err := db.WriteCursor(ctx, cursor); err != nil {
+	batch.Put(cursorKey, cursorToBytes(cursor))
+
+	err = db.commit(ctx, batch)
+	if db.cache != nil {
+		// a failed commit may still have applied part of the batch
+		db.cache.invalidate(batch)
+	}
+	if err != nil {
 		return 0, err
 	}
 
-	opCount := len(db.pendingOperations)
+	if db.changeLog != nil {
+		db.changeLog.next, db.changeLog.first = changeLogNext, changeLogFirst
+	}
+	db.setCommittedBlock(committedBlock(cursor))
+
+	if subscribed {
+		db.notifyFlush(&FlushEvent{Changes: changes, Cursor: cursor, Undo: db.pendingUndo})
+	}
+
+	opCount := len(db.pendingOperations) + len(db.pendingPrefixDeletions)
 	db.reset()
 
 	return opCount, nil
@@ -160,6 +412,15 @@ func (db *OperationDB) AddUndosOperations(ctx context.Context, blockNumber uint6
 func (db *OperationDB) GenerateUndoOperations(ctx context.Context, ops []*pbkv.KVOperation) (*pbkv.KVOperations, error) {
 	var undoOperations []*pbkv.KVOperation
 	for _, op := range ops {
+		if op.Type == pbkv.KVOperation_DELETE_PREFIX {
+			restoreOperations, err := db.undoDeletePrefix(ctx, op.Key)
+			if err != nil {
+				return nil, fmt.Errorf("getting previous values for prefix %s: %w", op.Key, err)
+			}
+			undoOperations = append(restoreOperations, undoOperations...)
+			continue
+		}
+
 		previousValue, err := db.store.Get(ctx, userKey(op.Key))
 		previousKeyExists := true
 		if err != nil {
@@ -168,7 +429,14 @@ func (db *OperationDB) GenerateUndoOperations(ctx context.Context, ops []*pbkv.K
 			}
 			previousKeyExists = false
 		}
-		undoOp := undoOperation(op, previousValue, previousKeyExists)
+		undoOp, err := db.withStoredExpiry(ctx, undoOperation(op, previousValue, previousKeyExists))
+		if err != nil {
+			return nil, err
+		}
+		if undoOp == nil {
+			// a DELETE of a missing key has nothing to restore
+			continue
+		}
 		undoOperations = append([]*pbkv.KVOperation{undoOp}, undoOperations...)
 	}
 	reversedKVOperations := &pbkv.KVOperations{Operations: undoOperations}
@@ -177,7 +445,7 @@ func (db *OperationDB) GenerateUndoOperations(ctx context.Context, ops []*pbkv.K
 
 func undoOperation(op *pbkv.KVOperation, previousValue []byte, previousKeyExists bool) *pbkv.KVOperation {
 	switch op.Type {
-	case pbkv.KVOperation_SET:
+	case pbkv.KVOperation_SET, pbkv.KVOperation_ADD, pbkv.KVOperation_INCREMENT, pbkv.KVOperation_APPEND:
 		if previousKeyExists {
 			return &pbkv.KVOperation{
 				Type:  pbkv.KVOperation_SET,
@@ -195,7 +463,7 @@ func undoOperation(op *pbkv.KVOperation, previousValue []byte, previousKeyExists
 			return &pbkv.KVOperation{
 				Type:  pbkv.KVOperation_SET,
 				Key:   op.Key,
-				Value: op.Value,
+				Value: previousValue,
 			}
 		}
 		return nil
@@ -204,7 +472,55 @@ func undoOperation(op *pbkv.KVOperation, previousValue []byte, previousKeyExists
 	}
 }
 
+// undoDeletePrefix captures every key/value currently stored under prefix so that
+// a reorg can restore them all.
+func (db *OperationDB) undoDeletePrefix(ctx context.Context, prefix string) ([]*pbkv.KVOperation, error) {
+	// rejected before the undo operations of every key are read, see AddOperation
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix must not be empty", ErrInvalidArguments)
+	}
+
+	itr := db.store.Prefix(ctx, userKey(prefix), 0)
+
+	var undoOperations []*pbkv.KVOperation
+	for itr.Next() {
+		it := itr.Item()
+		undoOp, err := db.withStoredExpiry(ctx, &pbkv.KVOperation{
+			Type:  pbkv.KVOperation_SET,
+			Key:   fromUserKey(it.Key),
+			Value: it.Value,
+		})
+		if err != nil {
+			return nil, err
+		}
+		undoOperations = append(undoOperations, undoOp)
+	}
+	if err := itr.Err(); err != nil {
+		return nil, err
+	}
+	return undoOperations, nil
+}
+
+func (db *OperationDB) prefixKeys(ctx context.Context, prefix string) (keys [][]byte, err error) {
+	itr := db.store.Prefix(ctx, userKey(prefix), 0, store.KeyOnly())
+	for itr.Next() {
+		keys = append(keys, itr.Item().Key)
+	}
+	if err := itr.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
 func (db *OperationDB) HandleBlockUndo(ctx context.Context, lastValidBlock uint64) error {
+	if db.versions != nil {
+		if err := db.undoVersions(ctx, lastValidBlock); err != nil {
+			return fmt.Errorf("undoing versions after block %d: %w", lastValidBlock, err)
+		}
+	}
+
+	db.pendingUndo = true
+
 	scanResult := db.store.Scan(ctx, undoKey(math.MaxUint64), undoKey(lastValidBlock), 0)
 	if scanResult.Err() != nil {
 		return fmt.Errorf("scanning undo operations for block %d: %w", lastValidBlock, scanResult.Err())
@@ -213,12 +529,15 @@ func (db *OperationDB) HandleBlockUndo(ctx context.Context, lastValidBlock uint6
 	var encodedOperations []byte
 
 	for scanResult.Next() {
+		db.undoneBlocks = append(db.undoneBlocks, blockFromUndoKey(scanResult.Item().Key))
 		encodedOperations = scanResult.Item().Value
 		err := proto.Unmarshal(encodedOperations, kvOperations)
 		if err != nil {
 			return fmt.Errorf("unmarshaling undo operations: %w", err)
 		}
-		db.AddOperations(kvOperations)
+		if err := db.AddOperations(ctx, kvOperations); err != nil {
+			return fmt.Errorf("adding undo operations: %w", err)
+		}
 	}
 	return nil
 }
@@ -229,61 +548,193 @@ func (db *OperationDB) DeleteUndoKeys(ctx context.Context, keys [][]byte) error
 
 func (db *OperationDB) reset() {
 	db.pendingOperations = make(map[string]*pbkv.KVOperation)
+	db.pendingPrefixDeletions = nil
 	db.undosOperations = make(map[uint64][]byte)
+	db.pendingUndo = false
+	db.undoneBlocks = nil
+	if db.versions != nil {
+		db.versions.reset()
+	}
+	if db.changeLog != nil {
+		db.changeLog.reset()
+	}
 }
 
-func (db *OperationDB) Get(ctx context.Context, key string) (val []byte, err error) {
+func (db *OperationDB) Get(ctx context.Context, key string, opts ...ReadOption) (val []byte, err error) {
+	options := NewReadOptions(opts...)
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if options.AtBlock != nil {
+		return db.getAt(ctx, key, *options.AtBlock)
+	}
+	if options.Final {
+		return db.getFinal(ctx, key)
+	}
+
+	var generation uint64
+	if db.cache != nil {
+		value, found, cached := db.cache.get(key)
+		if cached && !found {
+			return nil, ErrNotFound
+		}
+		if cached {
+			return value, nil
+		}
+		generation = db.cache.currentGeneration()
+	}
+
 	val, err = db.store.Get(ctx, userKey(key))
 	if err != nil && errors.Is(err, store.ErrNotFound) {
+		if db.cache != nil {
+			db.cache.put(generation, key, nil, false)
+		}
 		return nil, ErrNotFound
 	}
+	if err == nil && db.cache != nil {
+		db.cache.put(generation, key, val, true)
+	}
 	return
 }
 
-func (db *OperationDB) GetMany(ctx context.Context, keys []string) (values [][]byte, err error) {
+// GetMany returns one entry per key, in the order of keys, the entries of missing keys
+// having found unset. At most the query rows limit of keys can be requested at once.
+func (db *OperationDB) GetMany(ctx context.Context, keys []string) (entries []*pbkv.GetManyEntry, err error) {
 	if len(keys) == 0 {
 		return nil, fmt.Errorf("%w: you must specify at least one key", ErrInvalidArguments)
 	}
+	if len(keys) > db.QueryRowsLimit {
+		return nil, fmt.Errorf("%w: request value for 'keys' must have at most %d keys, but received %d", ErrInvalidArguments, db.QueryRowsLimit, len(keys))
+	}
+	if db.cache == nil {
+		return db.getMany(ctx, keys)
+	}
+
+	entries = make([]*pbkv.GetManyEntry, len(keys))
+	var missed []string
+	var missedIndexes []int
+	for i, key := range keys {
+		value, found, cached := db.cache.get(key)
+		if !cached {
+			missed = append(missed, key)
+			missedIndexes = append(missedIndexes, i)
+			continue
+		}
+		entries[i] = &pbkv.GetManyEntry{Key: key, Value: value, Found: found}
+	}
+	if len(missed) == 0 {
+		return entries, nil
+	}
+
+	generation := db.cache.currentGeneration()
+	missedEntries, err := db.getMany(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range missedEntries {
+		db.cache.put(generation, entry.Key, entry.Value, entry.Found)
+		entries[missedIndexes[i]] = entry
+	}
+	return entries, nil
+}
+
+// getMany reads the entries of keys from the store.
+func (db *OperationDB) getMany(ctx context.Context, keys []string) (entries []*pbkv.GetManyEntry, err error) {
 	userKeys := make([][]byte, len(keys))
 	for i := range keys {
 		userKeys[i] = userKey(keys[i])
 	}
 
+	// Stores either skip missing keys or stop at the first one, the keys left
+	// unresolved by the latter are then read one by one.
+	values := make(map[string][]byte, len(keys))
 	itr := db.store.BatchGet(ctx, userKeys)
 	for itr.Next() {
-		values = append(values, itr.Item().Value)
+		values[string(itr.Item().Key)] = itr.Item().Value
 	}
-	if err := itr.Err(); err != nil {
-		if err != nil && errors.Is(err, store.ErrNotFound) {
-			return nil, ErrNotFound
+	batchErr := itr.Err()
+	if batchErr != nil && !errors.Is(batchErr, store.ErrNotFound) {
+		return nil, batchErr
+	}
+
+	entries = make([]*pbkv.GetManyEntry, len(keys))
+	for i, key := range keys {
+		value, found := values[string(userKeys[i])]
+		if !found && batchErr != nil {
+			value, err = db.store.Get(ctx, userKeys[i])
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				return nil, err
+			}
+			found = err == nil
 		}
+		entries[i] = &pbkv.GetManyEntry{Key: key, Value: value, Found: found}
 	}
-	return values, nil
+	return entries, nil
 }
 
-func (db *OperationDB) GetByPrefix(ctx context.Context, prefix string, limit int) (values []*pbkv.KV, limitReached bool, err error) {
-	if limit == 0 {
-		limit = db.QueryRowsLimit
+func (db *OperationDB) GetByPrefix(ctx context.Context, prefix string, limit int, opts ...ReadOption) (values []*pbkv.KV, limitReached bool, err error) {
+	options := NewReadOptions(opts...)
+	if err := options.validate(); err != nil {
+		return nil, false, err
 	}
-	if limit < 0 || limit > db.QueryRowsLimit {
-		return nil, false, fmt.Errorf("%w: request value for 'limit' must be between 1 and %d, but received %d", ErrInvalidArguments, db.QueryRowsLimit, limit)
+	if limit, err = db.queryLimit(limit, options); err != nil {
+		return nil, false, err
 	}
 	if prefix == "" {
 		return nil, false, fmt.Errorf("%w: request value for 'prefix' must not be empty", ErrInvalidArguments)
 	}
 
-	itr := db.store.Prefix(ctx, userKey(prefix), limit+1)
+	start, end := prefix, ""
+	if options.PageToken != "" {
+		lastKey, err := resumeAfter(options.PageToken, pageTokenPrefix, options.Reverse, prefix)
+		if err != nil {
+			return nil, false, err
+		}
+		if options.Reverse {
+			end = lastKey
+		} else {
+			start = keyAfter(lastKey)
+		}
+	}
+
+	if options.AtBlock != nil {
+		endBytes := prefixEnd(versionKeyStart(prefix))
+		if end != "" {
+			endBytes = versionKeyStart(end)
+		}
+		return db.scanAt(ctx, versionKeyStart(start), endBytes, *options.AtBlock, limit, options)
+	}
+
+	if options.Final {
+		return db.scanFinal(ctx, prefix, start, end, limit, options, func(limit int) ([]*pbkv.KV, bool, error) {
+			return db.prefixLatest(ctx, prefix, start, end, limit, options)
+		})
+	}
+	return db.prefixLatest(ctx, prefix, start, end, limit, options)
+}
+
+// prefixLatest returns the latest values of the keys in [start, end) starting with
+// prefix, an empty end being the end of the prefix.
+func (db *OperationDB) prefixLatest(ctx context.Context, prefix, start, end string, limit int, options *ReadOptions) (values []*pbkv.KV, limitReached bool, err error) {
+	if options.Reverse {
+		endBytes := prefixEnd(userKey(prefix))
+		if end != "" {
+			endBytes = userKey(end)
+		}
+		return db.reverseScan(ctx, userKey(start), endBytes, limit, options)
+	}
+
+	itr := db.store.Prefix(ctx, userKey(prefix), limit+1, options.storeOptions()...)
+	if start != prefix {
+		itr = db.store.Scan(ctx, userKey(start), prefixEnd(userKey(prefix)), limit+1, options.storeOptions()...)
+	}
 	for itr.Next() {
 		if len(values) == limit {
 			limitReached = true
 			break
 		}
-		it := itr.Item()
 		// it.Key must be userKey because it matches prefix userKey(...)
-		values = append(values, &pbkv.KV{
-			Key:   fromUserKey(it.Key),
-			Value: it.Value,
-		})
+		values = append(values, options.keyValue(fromUserKey(itr.Item().Key), itr.Item().Value))
 	}
 	if err := itr.Err(); err != nil {
 		return nil, false, err
@@ -294,19 +745,61 @@ func (db *OperationDB) GetByPrefix(ctx context.Context, prefix string, limit int
 	return values, limitReached, nil
 }
 
-func (db *OperationDB) Scan(ctx context.Context, begin, exclusiveEnd string, limit int) (values []*pbkv.KV, limitReached bool, err error) {
-	if limit == 0 {
-		limit = db.QueryRowsLimit
+func (db *OperationDB) Scan(ctx context.Context, begin, exclusiveEnd string, limit int, opts ...ReadOption) (values []*pbkv.KV, limitReached bool, err error) {
+	options := NewReadOptions(opts...)
+	if err := options.validate(); err != nil {
+		return nil, false, err
+	}
+	if limit, err = db.queryLimit(limit, options); err != nil {
+		return nil, false, err
+	}
+
+	if options.PageToken != "" {
+		lastKey, err := resumeAfter(options.PageToken, pageTokenScan, options.Reverse, begin, exclusiveEnd)
+		if err != nil {
+			return nil, false, err
+		}
+		if options.Reverse {
+			exclusiveEnd = lastKey
+		} else {
+			begin = keyAfter(lastKey)
+		}
 	}
-	if limit < 0 || limit > db.QueryRowsLimit {
-		return nil, false, fmt.Errorf("%w: request value for 'limit' must be between 1 and %d, but received %d", ErrInvalidArguments, db.QueryRowsLimit, limit)
+
+	if options.AtBlock != nil {
+		endBytes := prefixEnd(versionKeyPrefix)
+		if exclusiveEnd != "" {
+			endBytes = versionKeyStart(exclusiveEnd)
+		}
+		return db.scanAt(ctx, versionKeyStart(begin), endBytes, *options.AtBlock, limit, options)
+	}
+
+	if options.Final {
+		return db.scanFinal(ctx, "", begin, exclusiveEnd, limit, options, func(limit int) ([]*pbkv.KV, bool, error) {
+			return db.scanLatest(ctx, begin, exclusiveEnd, limit, options)
+		})
+	}
+	return db.scanLatest(ctx, begin, exclusiveEnd, limit, options)
+}
+
+// scanLatest returns the latest values of the keys in [begin, exclusiveEnd), an empty
+// exclusiveEnd being open-ended.
+func (db *OperationDB) scanLatest(ctx context.Context, begin, exclusiveEnd string, limit int, options *ReadOptions) (values []*pbkv.KV, limitReached bool, err error) {
+	if options.Reverse {
+		// InfiniteEndBytes would start the scan among the internal keyspaces following
+		// the user keys, an open-ended range stops at the end of the user keys instead
+		endBytes := prefixEnd([]byte{userKeyPrefix})
+		if exclusiveEnd != "" {
+			endBytes = userKey(exclusiveEnd)
+		}
+		return db.reverseScan(ctx, userKey(begin), endBytes, limit, options)
 	}
 
 	endBytes := InfiniteEndBytes
 	if exclusiveEnd != "" {
 		endBytes = userKey(exclusiveEnd)
 	}
-	itr := db.store.Scan(ctx, userKey(begin), endBytes, limit+1)
+	itr := db.store.Scan(ctx, userKey(begin), endBytes, limit+1, options.storeOptions()...)
 	for itr.Next() {
 		if !isUserKey(itr.Item().Key) {
 			db.logger.Debug("skipping non-user-key", zap.String("key", string(itr.Item().Key)))
@@ -316,11 +809,7 @@ func (db *OperationDB) Scan(ctx context.Context, begin, exclusiveEnd string, lim
 			limitReached = true
 			break
 		}
-		it := itr.Item()
-		values = append(values, &pbkv.KV{
-			Key:   fromUserKey(it.Key),
-			Value: it.Value,
-		})
+		values = append(values, options.keyValue(fromUserKey(itr.Item().Key), itr.Item().Value))
 	}
 	if err := itr.Err(); err != nil {
 		return nil, false, err
@@ -331,6 +820,55 @@ func (db *OperationDB) Scan(ctx context.Context, begin, exclusiveEnd string, lim
 	return values, limitReached, nil
 }
 
+// Count returns the number of keys in [begin, exclusiveEnd), an empty exclusiveEnd
+// counting up to the last key. Counting stops past the query keys limit, limitReached
+// being then true.
+func (db *OperationDB) Count(ctx context.Context, begin, exclusiveEnd string) (count uint64, limitReached bool, err error) {
+	endBytes := prefixEnd([]byte{userKeyPrefix})
+	if exclusiveEnd != "" {
+		endBytes = userKey(exclusiveEnd)
+	}
+	return db.countKeys(db.store.Scan(ctx, userKey(begin), endBytes, db.QueryKeysLimit+1, store.KeyOnly()))
+}
+
+// CountPrefix returns the number of keys starting with prefix, see Count.
+func (db *OperationDB) CountPrefix(ctx context.Context, prefix string) (count uint64, limitReached bool, err error) {
+	if prefix == "" {
+		return 0, false, fmt.Errorf("%w: request value for 'prefix' must not be empty", ErrInvalidArguments)
+	}
+	return db.countKeys(db.store.Prefix(ctx, userKey(prefix), db.QueryKeysLimit+1, store.KeyOnly()))
+}
+
+func (db *OperationDB) countKeys(itr *store.Iterator) (count uint64, limitReached bool, err error) {
+	for itr.Next() {
+		if count == uint64(db.QueryKeysLimit) {
+			limitReached = true
+			break
+		}
+		count++
+	}
+	if err := itr.Err(); err != nil {
+		return 0, false, err
+	}
+	return count, limitReached, nil
+}
+
+// queryLimit resolves the limit of a read, 0 meaning the highest one allowed, the
+// query rows limit or, for keys only reads, the query keys limit.
+func (db *OperationDB) queryLimit(limit int, options *ReadOptions) (int, error) {
+	maxLimit := db.QueryRowsLimit
+	if options.KeysOnly {
+		maxLimit = db.QueryKeysLimit
+	}
+	if limit == 0 {
+		return maxLimit, nil
+	}
+	if limit < 0 || limit > maxLimit {
+		return 0, fmt.Errorf("%w: request value for 'limit' must be between 1 and %d, but received %d", ErrInvalidArguments, maxLimit, limit)
+	}
+	return limit, nil
+}
+
 func userKey(k string) []byte {
 	out := make([]byte, len(k)+1)
 	out[0] = userKeyPrefix
@@ -345,6 +883,10 @@ func undoKey(num uint64) []byte {
 	return numBytes
 }
 
+func blockFromUndoKey(k []byte) uint64 {
+	return math.MaxUint64 - binary.BigEndian.Uint64(k[len(undoPrefix):])
+}
+
 func isUserKey(k []byte) bool {
 	if len(k) > 1 && k[0] == userKeyPrefix {
 		return true
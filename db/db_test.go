@@ -2,16 +2,29 @@ package db
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/kvdb/store"
 	_ "github.com/streamingfast/kvdb/store/badger3"
 	"github.com/streamingfast/logging"
+	sink "github.com/streamingfast/substreams-sink"
 	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
 	"github.com/test-go/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 func TestDB_HandleOperations(t *testing.T) {
@@ -107,7 +120,7 @@ func TestDB_HandleOperations(t *testing.T) {
 			require.NoError(t, err)
 
 			for _, block := range c.blocks {
-				err = db.HandleOperations(ctx, block.blockNumber, block.finalBlockHeight, bstream.StepNew, block.operations)
+				err = db.HandleOperations(ctx, testBlock(block.blockNumber), block.finalBlockHeight, bstream.StepNew, block.operations)
 				require.NoError(t, err)
 				_, err = db.Flush(ctx, nil)
 				require.NoError(t, err)
@@ -247,7 +260,7 @@ func TestDB_HandleUndo(t *testing.T) {
 			require.NoError(t, err)
 
 			for _, block := range c.blocks {
-				err = db.HandleOperations(ctx, block.blockNumber, block.finalBlockHeight, bstream.StepNew, block.operations)
+				err = db.HandleOperations(ctx, testBlock(block.blockNumber), block.finalBlockHeight, bstream.StepNew, block.operations)
 				require.NoError(t, err)
 				_, err = db.Flush(ctx, nil)
 				require.NoError(t, err)
@@ -272,6 +285,230 @@ func TestDB_HandleUndo(t *testing.T) {
 	}
 }
 
+func TestDB_HandleUndoOperations(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-undo-operations"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test20")
+
+	type blockOperations struct {
+		blockNumber      uint64
+		operations       *pbkv.KVOperations
+		finalBlockHeight uint64
+	}
+	cases := []struct {
+		name           string
+		blocks         []blockOperations
+		lastValidBlock uint64
+		expectedKV     map[string]string
+	}{
+		{
+			name: "restore deleted prefix",
+			blocks: []blockOperations{
+				{
+					blockNumber: 2,
+					operations: &pbkv.KVOperations{
+						Operations: []*pbkv.KVOperation{
+							{Key: "entity.1.a", Value: []byte("a"), Type: pbkv.KVOperation_SET},
+							{Key: "entity.1.b", Value: []byte("b"), Type: pbkv.KVOperation_SET},
+						},
+					},
+					finalBlockHeight: 1,
+				},
+				{
+					blockNumber: 3,
+					operations: &pbkv.KVOperations{
+						Operations: []*pbkv.KVOperation{
+							{Key: "entity.1.", Type: pbkv.KVOperation_DELETE_PREFIX},
+						},
+					},
+					finalBlockHeight: 1,
+				},
+			},
+			lastValidBlock: 2,
+			expectedKV:     map[string]string{"kentity.1.a": "a", "kentity.1.b": "b"},
+		},
+
+		{
+			name: "restore counter",
+			blocks: []blockOperations{
+				{
+					blockNumber: 2,
+					operations: &pbkv.KVOperations{
+						Operations: []*pbkv.KVOperation{
+							{Key: "counter", Value: []byte("5"), Type: pbkv.KVOperation_ADD, NumericEncoding: pbkv.KVOperation_DECIMAL_STRING},
+						},
+					},
+					finalBlockHeight: 1,
+				},
+				{
+					blockNumber: 3,
+					operations: &pbkv.KVOperations{
+						Operations: []*pbkv.KVOperation{
+							{Key: "counter", Value: []byte("3"), Type: pbkv.KVOperation_ADD, NumericEncoding: pbkv.KVOperation_DECIMAL_STRING},
+							{Key: "counter", Type: pbkv.KVOperation_INCREMENT, NumericEncoding: pbkv.KVOperation_DECIMAL_STRING},
+						},
+					},
+					finalBlockHeight: 1,
+				},
+			},
+			lastValidBlock: 2,
+			expectedKV:     map[string]string{"kcounter": "5"},
+		},
+
+		{
+			name: "truncate appended list",
+			blocks: []blockOperations{
+				{
+					blockNumber: 2,
+					operations: &pbkv.KVOperations{
+						Operations: []*pbkv.KVOperation{
+							{Key: "events", Value: []byte("a"), Type: pbkv.KVOperation_APPEND},
+						},
+					},
+					finalBlockHeight: 1,
+				},
+				{
+					blockNumber: 3,
+					operations: &pbkv.KVOperations{
+						Operations: []*pbkv.KVOperation{
+							{Key: "events", Value: []byte("b"), Type: pbkv.KVOperation_APPEND},
+							{Key: "events", Value: []byte("c"), Type: pbkv.KVOperation_APPEND},
+						},
+					},
+					finalBlockHeight: 1,
+				},
+			},
+			lastValidBlock: 2,
+			expectedKV:     map[string]string{"kevents": "a"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.NoError(t, os.RemoveAll(dbPath))
+			db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+			require.NoError(t, err)
+			defer db.store.Close()
+
+			for _, block := range c.blocks {
+				err = db.HandleOperations(ctx, testBlock(block.blockNumber), block.finalBlockHeight, bstream.StepNew, block.operations)
+				require.NoError(t, err)
+				_, err = db.Flush(ctx, nil)
+				require.NoError(t, err)
+			}
+
+			err = db.HandleBlockUndo(ctx, c.lastValidBlock)
+			require.NoError(t, err)
+
+			_, err = db.Flush(ctx, nil)
+			require.NoError(t, err)
+
+			scanOutput := db.store.Scan(ctx, userKey(""), []byte{'l'}, 0)
+			require.NoError(t, scanOutput.Err())
+
+			currentState := map[string]string{}
+			for scanOutput.Next() {
+				currentState[string(scanOutput.Item().Key)] = string(scanOutput.Item().Value)
+			}
+
+			require.Equal(t, c.expectedKV, currentState)
+		})
+	}
+}
+
+func TestDB_UndoDeleteMissingKey(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-undo-delete-missing"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test22")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "key.1", Value: []byte("value.1"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	// the DELETE of a missing key has nothing to restore
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "key.1", Value: []byte("value.1bis"), Type: pbkv.KVOperation_SET},
+			{Key: "missing", Type: pbkv.KVOperation_DELETE},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleBlockUndo(ctx, 2))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	value, err := db.Get(ctx, "key.1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value.1"), value)
+
+	_, err = db.Get(ctx, "missing")
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestDB_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-delete-prefix"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test21")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "entity.1.a", Value: []byte("a"), Type: pbkv.KVOperation_SET},
+			{Key: "entity.1.b", Value: []byte("b"), Type: pbkv.KVOperation_SET},
+			{Key: "entity.2.a", Value: []byte("a"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	// operations received before the deletion are superseded, later ones still apply
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "entity.1.c", Value: []byte("c"), Type: pbkv.KVOperation_SET},
+			{Key: "entity.1.", Type: pbkv.KVOperation_DELETE_PREFIX},
+			{Key: "entity.1.d", Value: []byte("d"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	scanOutput := db.store.Scan(ctx, userKey(""), []byte{'l'}, 0)
+	require.NoError(t, scanOutput.Err())
+	var remaining []string
+	for scanOutput.Next() {
+		remaining = append(remaining, string(scanOutput.Item().Key))
+	}
+	require.Equal(t, []string{"kentity.1.d", "kentity.2.a"}, remaining)
+
+	// an empty prefix would delete every key
+	err = db.HandleOperations(ctx, testBlock(4), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "", Type: pbkv.KVOperation_DELETE_PREFIX},
+		},
+	})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrInvalidArguments))
+	require.NotContains(t, db.undosOperations, uint64(4))
+}
+
 func TestDB_UndoOperation(t *testing.T) {
 	type foundValue struct {
 		previousKeyExists bool
@@ -346,3 +583,1456 @@ func TestDB_UndoOperation(t *testing.T) {
 	}
 
 }
+
+func TestDB_UndoDeleteRestoresPreviousValue(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-undo-delete"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test23")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "key.1", Value: []byte("value.1"), Type: pbkv.KVOperation_SET},
+			{Key: "key.2", Value: []byte("value.2"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	// the value of a DELETE is meaningless, the undo restores the stored value
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "key.1", Type: pbkv.KVOperation_DELETE},
+			{Key: "key.2", Value: []byte("other"), Type: pbkv.KVOperation_DELETE},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleBlockUndo(ctx, 2))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	value, err := db.Get(ctx, "key.1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value.1"), value)
+
+	value, err = db.Get(ctx, "key.2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value.2"), value)
+}
+
+func TestDB_RecoverPendingFlush(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-wal"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test3")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.store.Put(ctx, userKey("key.2"), []byte("value.2")))
+	require.NoError(t, db.store.FlushPuts(ctx))
+
+	batch := &flushBatch{}
+	batch.Put(userKey("key.1"), []byte("value.1"))
+	batch.Delete(userKey("key.2"))
+	batch.Put(undoKey(3), []byte("undo"))
+
+	// Simulates a crash right after the marker was committed but before it was applied
+	_, err = db.writeMarker(ctx, batch.encode())
+	require.NoError(t, err)
+
+	// A leftover chunk from a marker that never reached its commit key must be discarded
+	require.NoError(t, db.store.Put(ctx, walChunkKey(1), []byte("garbage")))
+	require.NoError(t, db.store.FlushPuts(ctx))
+
+	require.NoError(t, db.RecoverPendingFlush(ctx))
+
+	currentState := map[string]string{}
+	scanOutput := db.store.Scan(ctx, []byte{0}, InfiniteEndBytes, 0)
+	for scanOutput.Next() {
+		currentState[string(scanOutput.Item().Key)] = string(scanOutput.Item().Value)
+	}
+	require.NoError(t, scanOutput.Err())
+
+	require.Equal(t, map[string]string{
+		"kkey.1":               "value.1",
+		string(undoKey(3)):     "undo",
+		string(walChunkKey(1)): "garbage",
+	}, currentState)
+
+	require.NoError(t, db.RecoverPendingFlush(ctx))
+
+	_, err = db.store.Get(ctx, walChunkKey(1))
+	require.Equal(t, store.ErrNotFound, err)
+}
+
+func TestDB_AtomicFlush(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-atomic"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test19")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	storedKeys := func() (out []string) {
+		scanOutput := db.store.Scan(ctx, []byte{0}, InfiniteEndBytes, 0)
+		for scanOutput.Next() {
+			out = append(out, string(scanOutput.Item().Key))
+		}
+		require.NoError(t, scanOutput.Err())
+		return out
+	}
+	flush := func(blockNum uint64, ops ...*pbkv.KVOperation) {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+		_, err := db.Flush(ctx, testCursor(blockNum))
+		require.NoError(t, err)
+	}
+
+	flush(1, &pbkv.KVOperation{Key: "a", Value: []byte("1"), Type: pbkv.KVOperation_SET}, &pbkv.KVOperation{Key: "b", Value: []byte("1"), Type: pbkv.KVOperation_SET})
+	flush(2, &pbkv.KVOperation{Key: "a", Type: pbkv.KVOperation_DELETE})
+	require.Equal(t, []string{"kb", string(cursorKey), string(undoKey(2)), string(undoKey(1))}, storedKeys())
+
+	value, err := db.Get(ctx, "b")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), value)
+}
+
+func TestDB_ApplyNumericOperation(t *testing.T) {
+	int64Bytes := func(v int64) []byte { return binary.BigEndian.AppendUint64(nil, uint64(v)) }
+	uint64Bytes := func(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+	cases := []struct {
+		name          string
+		operation     *pbkv.KVOperation
+		current       []byte
+		expectedValue []byte
+		expectedError bool
+	}{
+		{
+			name:          "add int64 to missing key",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_ADD, Value: int64Bytes(-5), NumericEncoding: pbkv.KVOperation_INT64_BIG_ENDIAN},
+			expectedValue: int64Bytes(-5),
+		},
+		{
+			name:          "add int64",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_ADD, Value: int64Bytes(10), NumericEncoding: pbkv.KVOperation_INT64_BIG_ENDIAN},
+			current:       int64Bytes(-5),
+			expectedValue: int64Bytes(5),
+		},
+		{
+			name:          "add int64 overflow",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_ADD, Value: int64Bytes(1), NumericEncoding: pbkv.KVOperation_INT64_BIG_ENDIAN},
+			current:       int64Bytes(math.MaxInt64),
+			expectedError: true,
+		},
+		{
+			name:          "increment uint64",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_INCREMENT, NumericEncoding: pbkv.KVOperation_UINT64_BIG_ENDIAN},
+			current:       uint64Bytes(math.MaxInt64),
+			expectedValue: uint64Bytes(math.MaxInt64 + 1),
+		},
+		{
+			name:          "add uint64 underflow",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_ADD, Value: int64Bytes(-2), NumericEncoding: pbkv.KVOperation_UINT64_BIG_ENDIAN},
+			current:       uint64Bytes(1),
+			expectedError: true,
+		},
+		{
+			name:          "add decimal string",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_ADD, Value: []byte("-100000000000000000000"), NumericEncoding: pbkv.KVOperation_DECIMAL_STRING},
+			current:       []byte("99999999999999999999"),
+			expectedValue: []byte("-1"),
+		},
+		{
+			name:          "invalid current value",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_INCREMENT, NumericEncoding: pbkv.KVOperation_INT64_BIG_ENDIAN},
+			current:       []byte("abc"),
+			expectedError: true,
+		},
+		{
+			name:          "unset encoding",
+			operation:     &pbkv.KVOperation{Type: pbkv.KVOperation_INCREMENT},
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value, err := applyNumericOperation(c.operation, c.current, c.current != nil)
+			if c.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedValue, value)
+		})
+	}
+}
+
+func TestDB_AppendOperation(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-append"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test4")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "raw", Value: []byte("ab"), Type: pbkv.KVOperation_APPEND},
+			{Key: "list", Value: []byte("ab"), Type: pbkv.KVOperation_APPEND, AppendEncoding: pbkv.KVOperation_LENGTH_PREFIXED},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "raw", Value: []byte("c"), Type: pbkv.KVOperation_APPEND},
+			{Key: "raw", Value: []byte("d"), Type: pbkv.KVOperation_APPEND},
+			{Key: "list", Value: []byte("c"), Type: pbkv.KVOperation_APPEND, AppendEncoding: pbkv.KVOperation_LENGTH_PREFIXED},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	value, err := db.Get(ctx, "raw")
+	require.NoError(t, err)
+	require.Equal(t, []byte("abcd"), value)
+
+	value, err = db.Get(ctx, "list")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 2, 'a', 'b', 0, 0, 0, 1, 'c'}, value)
+}
+
+func TestDB_OperationsOrdering(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-ordinal"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test5")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 4, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 1, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "key.1", Value: []byte("ordinal.3"), Ordinal: 3, Type: pbkv.KVOperation_SET},
+			{Key: "key.1", Value: []byte("ordinal.1"), Ordinal: 1, Type: pbkv.KVOperation_SET},
+			{Key: "key.2", Value: []byte("first"), Ordinal: 2, Type: pbkv.KVOperation_SET},
+			{Key: "key.2", Value: []byte("second"), Ordinal: 2, Type: pbkv.KVOperation_SET},
+			{Key: "list", Value: []byte("b"), Ordinal: 5, Type: pbkv.KVOperation_APPEND},
+			{Key: "list", Value: []byte("a"), Ordinal: 4, Type: pbkv.KVOperation_APPEND},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	entries, err := db.GetMany(ctx, []string{"key.1", "key.2", "list"})
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.GetManyEntry{
+		{Key: "key.1", Value: []byte("ordinal.3"), Found: true},
+		{Key: "key.2", Value: []byte("second"), Found: true},
+		{Key: "list", Value: []byte("ab"), Found: true},
+	}, entries)
+
+	entries, err = db.GetMany(ctx, []string{"missing.1", "key.2", "missing.2", "key.1"})
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.GetManyEntry{
+		{Key: "missing.1"},
+		{Key: "key.2", Value: []byte("second"), Found: true},
+		{Key: "missing.2"},
+		{Key: "key.1", Value: []byte("ordinal.3"), Found: true},
+	}, entries)
+
+	_, err = db.GetMany(ctx, []string{"key.1", "key.2", "list", "missing.1", "missing.2"})
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+}
+
+func TestDB_VersionedKeys(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-versions"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test6")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithVersionedKeys(0))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	_, err = db.Get(ctx, "a", AtBlock(1))
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+
+	blocks := []*pbkv.KVOperations{
+		{Operations: []*pbkv.KVOperation{
+			{Key: "a", Value: []byte("a.1"), Type: pbkv.KVOperation_SET},
+			{Key: "b", Value: []byte("b.1"), Type: pbkv.KVOperation_SET},
+		}},
+		{Operations: []*pbkv.KVOperation{
+			{Key: "a", Value: []byte("a.2"), Type: pbkv.KVOperation_SET},
+			{Key: "b", Type: pbkv.KVOperation_DELETE},
+		}},
+		{Operations: []*pbkv.KVOperation{
+			{Key: "a", Type: pbkv.KVOperation_DELETE_PREFIX},
+			{Key: "c", Value: []byte("c.3"), Type: pbkv.KVOperation_SET},
+		}},
+	}
+	for i, ops := range blocks {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(uint64(i+1)), 0, bstream.StepNew, ops))
+		_, err = db.Flush(ctx, nil)
+		require.NoError(t, err)
+	}
+
+	value, err := db.Get(ctx, "a", AtBlock(1))
+	require.NoError(t, err)
+	require.Equal(t, []byte("a.1"), value)
+
+	value, err = db.Get(ctx, "a", AtBlock(2))
+	require.NoError(t, err)
+	require.Equal(t, []byte("a.2"), value)
+
+	_, err = db.Get(ctx, "a", AtBlock(3))
+	require.Equal(t, ErrNotFound, err)
+
+	values, _, err := db.Scan(ctx, "", "", 0, AtBlock(1))
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.KV{{Key: "a", Value: []byte("a.1")}, {Key: "b", Value: []byte("b.1")}}, values)
+
+	values, _, err = db.Scan(ctx, "", "", 0, AtBlock(3))
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.KV{{Key: "c", Value: []byte("c.3")}}, values)
+
+	values, _, err = db.GetByPrefix(ctx, "a", 0, AtBlock(2))
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.KV{{Key: "a", Value: []byte("a.2")}}, values)
+
+	// Undoing block 3 removes its versions
+	require.NoError(t, db.HandleBlockUndo(ctx, 2))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	value, err = db.Get(ctx, "a", AtBlock(3))
+	require.NoError(t, err)
+	require.Equal(t, []byte("a.2"), value)
+
+	_, err = db.Get(ctx, "c", AtBlock(3))
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestDB_VersionsRetention(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-versions-retention"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test7")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithVersionedKeys(1))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	for blockNum := uint64(1); blockNum <= 3; blockNum++ {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), blockNum, bstream.StepNew, &pbkv.KVOperations{
+			Operations: []*pbkv.KVOperation{
+				{Key: "a", Value: []byte(fmt.Sprintf("a.%d", blockNum)), Type: pbkv.KVOperation_SET},
+			},
+		}))
+		_, err = db.Flush(ctx, nil)
+		require.NoError(t, err)
+	}
+
+	_, err = db.Get(ctx, "a", AtBlock(1))
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+
+	value, err := db.Get(ctx, "a", AtBlock(2))
+	require.NoError(t, err)
+	require.Equal(t, []byte("a.2"), value)
+
+	itr := db.store.Prefix(ctx, versionKeyStart("a"), 0, store.KeyOnly())
+	var versionKeys [][]byte
+	for itr.Next() {
+		versionKeys = append(versionKeys, itr.Item().Key)
+	}
+	require.NoError(t, itr.Err())
+	require.Equal(t, [][]byte{versionKey("a", 3), versionKey("a", 2)}, versionKeys)
+}
+
+func TestDB_VersionsSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-versions-snapshot"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test27")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(5), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "a", Value: []byte("a.5"), Type: pbkv.KVOperation_SET},
+			{Key: "b", Value: []byte("b.5"), Type: pbkv.KVOperation_SET},
+			{Key: "c", Value: []byte("c.5"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(5))
+	require.NoError(t, err)
+
+	// enabling versions snapshots the keys stored at the block of the cursor
+	db.versions = newVersions(0)
+	for blockNum := uint64(6); blockNum <= 8; blockNum++ {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{
+			Operations: []*pbkv.KVOperation{
+				{Key: "a", Value: []byte(fmt.Sprintf("a.%d", blockNum)), Type: pbkv.KVOperation_SET},
+				{Key: "b", Type: pbkv.KVOperation_DELETE},
+				{Key: "d", Value: []byte(fmt.Sprintf("d.%d", blockNum)), Type: pbkv.KVOperation_SET},
+			},
+		}))
+		_, err = db.Flush(ctx, testCursor(blockNum))
+		require.NoError(t, err)
+	}
+
+	_, err = db.store.Get(ctx, walCommitKey)
+	require.Equal(t, store.ErrNotFound, err)
+
+	values, limitReached, err := db.Scan(ctx, "", "", 2, AtBlock(5))
+	require.NoError(t, err)
+	require.True(t, limitReached)
+	require.Equal(t, []*pbkv.KV{{Key: "a", Value: []byte("a.5")}, {Key: "b", Value: []byte("b.5")}}, values)
+
+	values, limitReached, err = db.Scan(ctx, "", "", 3, AtBlock(5))
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Len(t, values, 3)
+
+	// deleted keys are skipped, the limit counting the keys having a value
+	values, limitReached, err = db.Scan(ctx, "", "", 2, AtBlock(7))
+	require.NoError(t, err)
+	require.True(t, limitReached)
+	require.Equal(t, []*pbkv.KV{{Key: "a", Value: []byte("a.7")}, {Key: "c", Value: []byte("c.5")}}, values)
+
+	values, limitReached, err = db.Scan(ctx, "b", "", 3, AtBlock(6))
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, []*pbkv.KV{{Key: "c", Value: []byte("c.5")}, {Key: "d", Value: []byte("d.6")}}, values)
+}
+
+func TestDB_VersionKey(t *testing.T) {
+	keys := []string{"", "a", "a\x00", "a\x00b", "a\x01", "ab", "b"}
+	for i, key := range keys {
+		decodedKey, blockNum, err := decodeVersionKey(versionKey(key, 42))
+		require.NoError(t, err)
+		require.Equal(t, key, decodedKey)
+		require.Equal(t, uint64(42), blockNum)
+
+		if i > 0 {
+			require.True(t, string(versionKey(keys[i-1], 0)) < string(versionKey(key, math.MaxUint64)))
+		}
+	}
+}
+
+func TestDB_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-subscribe"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test8")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 0, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	subscription := db.Subscribe(3)
+	lagging := db.Subscribe(0)
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(1), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "a.1", Value: []byte("1"), Type: pbkv.KVOperation_SET},
+			{Key: "a.2", Value: []byte("2"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "a.", Type: pbkv.KVOperation_DELETE_PREFIX},
+		},
+	}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleBlockUndo(ctx, 1))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	event := <-subscription.Events()
+	require.False(t, event.Undo)
+	require.Equal(t, []string{"a.1:SET", "a.2:SET"}, changes(event))
+
+	event = <-subscription.Events()
+	require.False(t, event.Undo)
+	require.Equal(t, []string{"a.1:DELETE", "a.2:DELETE"}, changes(event))
+
+	event = <-subscription.Events()
+	require.True(t, event.Undo)
+	require.Equal(t, []string{"a.1:SET", "a.2:SET"}, changes(event))
+
+	_, open := <-lagging.Events()
+	require.False(t, open)
+	require.Equal(t, ErrSubscriptionLagging, lagging.Err())
+
+	subscription.Close()
+	_, open = <-subscription.Events()
+	require.False(t, open)
+	require.NoError(t, subscription.Err())
+}
+
+func changes(event *FlushEvent) (out []string) {
+	for _, op := range event.Changes {
+		out = append(out, fmt.Sprintf("%s:%s", op.Key, op.Type))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestDB_ChangeLog(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-change-log"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test9")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithChangeLog(0, 0))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(1), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "a.1", Value: []byte("1"), Type: pbkv.KVOperation_SET},
+			{Key: "a.2", Value: []byte("2"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(1))
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "a.1", Value: []byte("1bis"), Type: pbkv.KVOperation_SET},
+			{Key: "a.2", Type: pbkv.KVOperation_DELETE},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(2))
+	require.NoError(t, err)
+
+	require.NoError(t, db.HandleBlockUndo(ctx, 1))
+	_, err = db.Flush(ctx, testCursor(1))
+	require.NoError(t, err)
+
+	entries, next, limitReached, err := db.ReadChanges(ctx, 0, 0)
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, uint64(7), next)
+	require.Equal(t, []string{
+		"1@1 SET a.1 <nil> -> 1",
+		"2@1 SET a.2 <nil> -> 2",
+		"3@2 SET a.1 1 -> 1bis",
+		"4@2 DELETE a.2 2 -> <nil>",
+		"5@1 SET a.2 <nil> -> 2 (undo)",
+		"6@1 SET a.1 1bis -> 1 (undo)",
+	}, changeLogEntries(entries))
+	require.Equal(t, "block-1", entries[0].BlockId)
+
+	entries, next, limitReached, err = db.ReadChanges(ctx, 3, 2)
+	require.NoError(t, err)
+	require.True(t, limitReached)
+	require.Equal(t, uint64(5), next)
+	require.Len(t, entries, 2)
+
+	entries, next, _, err = db.ReadChanges(ctx, 7, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	require.Equal(t, uint64(7), next)
+}
+
+func TestDB_ChangeLogBlocks(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-change-log-blocks"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test26")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithChangeLog(0, 0))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(1), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{{Key: "b.1", Value: []byte("1"), Type: pbkv.KVOperation_SET}},
+	}))
+	_, err = db.Flush(ctx, testCursor(1))
+	require.NoError(t, err)
+
+	// blocks 2 and 3 are committed by a single flush
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 0, bstream.StepIrreversible, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "z", Value: []byte("2"), Type: pbkv.KVOperation_SET, Ordinal: 2},
+			{Key: "a", Value: []byte("1"), Type: pbkv.KVOperation_SET, Ordinal: 1},
+			{Key: "z", Value: []byte("3"), Type: pbkv.KVOperation_SET, Ordinal: 3},
+			{Key: "b.2", Value: []byte("2"), Type: pbkv.KVOperation_SET, Ordinal: 4},
+		},
+	}))
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 0, bstream.StepIrreversible, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "b.", Type: pbkv.KVOperation_DELETE_PREFIX},
+			{Key: "a", Type: pbkv.KVOperation_DELETE},
+			{Key: "b.1", Value: []byte("3"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(3))
+	require.NoError(t, err)
+
+	entries, _, _, err := db.ReadChanges(ctx, 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"2@2 SET a <nil> -> 1",
+		"3@2 SET z <nil> -> 2",
+		"4@2 SET z 2 -> 3",
+		"5@2 SET b.2 <nil> -> 2",
+		"6@3 DELETE b.1 1 -> <nil>",
+		"7@3 DELETE b.2 2 -> <nil>",
+		"8@3 DELETE a 1 -> <nil>",
+		"9@3 SET b.1 <nil> -> 3",
+	}, changeLogEntries(entries))
+	require.Equal(t, "block-2", entries[0].BlockId)
+	require.Equal(t, "block-3", entries[len(entries)-1].BlockId)
+}
+
+func TestDB_ChangeLogRetention(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-change-log-retention"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test10")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithChangeLog(5, 1))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	// block n sets n keys, except block 4 setting a single one
+	for blockNum := uint64(1); blockNum <= 4; blockNum++ {
+		var ops []*pbkv.KVOperation
+		for i := uint64(0); i < blockNum && (blockNum < 4 || i < 1); i++ {
+			ops = append(ops, &pbkv.KVOperation{Key: fmt.Sprintf("%d.%d", blockNum, i), Value: []byte("v"), Type: pbkv.KVOperation_SET})
+		}
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+		_, err = db.Flush(ctx, testCursor(blockNum))
+		require.NoError(t, err)
+
+		switch blockNum {
+		case 2:
+			_, _, _, err := db.ReadChanges(ctx, 1, 0)
+			require.NoError(t, err)
+		case 3:
+			// 6 entries, the oldest one is purged by the entries bound
+			_, _, _, err := db.ReadChanges(ctx, 1, 0)
+			require.True(t, errors.Is(err, ErrChangesPurged))
+		}
+	}
+
+	// 7 entries, the ones of block 2 are purged by the blocks bound
+	_, _, _, err = db.ReadChanges(ctx, 3, 0)
+	require.True(t, errors.Is(err, ErrChangesPurged))
+
+	entries, _, _, err := db.ReadChanges(ctx, 4, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, uint64(3), entries[0].BlockNumber)
+
+	itr := db.store.Prefix(ctx, changeLogEntryPrefix, 0, store.KeyOnly())
+	var keys [][]byte
+	for itr.Next() {
+		keys = append(keys, itr.Item().Key)
+	}
+	require.NoError(t, itr.Err())
+	require.Equal(t, [][]byte{changeLogKey(4), changeLogKey(5), changeLogKey(6), changeLogKey(7)}, keys)
+}
+
+func TestDB_KeyExpiry(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-key-expiry"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test11")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	handleBlock := func(blockNum uint64, blockTime time.Time, ops ...*pbkv.KVOperation) {
+		expiredOps, err := db.ExpiredOperations(ctx, blockNum, blockTime)
+		require.NoError(t, err)
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{Operations: append(expiredOps, ops...)}))
+		_, err = db.Flush(ctx, testCursor(blockNum))
+		require.NoError(t, err)
+	}
+	expiredKeys := func(blockNum uint64, blockTime time.Time) (keys []string) {
+		expiredOps, err := db.ExpiredOperations(ctx, blockNum, blockTime)
+		require.NoError(t, err)
+		for _, op := range expiredOps {
+			keys = append(keys, op.Key)
+		}
+		return keys
+	}
+
+	handleBlock(1, time.Unix(900, 0),
+		&pbkv.KVOperation{Key: "a", Value: []byte("a"), Type: pbkv.KVOperation_SET, Expiry: &pbkv.KVOperation_ExpiresAtBlock{ExpiresAtBlock: 3}},
+		&pbkv.KVOperation{Key: "b", Value: []byte("b"), Type: pbkv.KVOperation_SET, Expiry: &pbkv.KVOperation_ExpiresAtTimestamp{ExpiresAtTimestamp: 1000}},
+		&pbkv.KVOperation{Key: "c", Value: []byte("c"), Type: pbkv.KVOperation_SET, Expiry: &pbkv.KVOperation_ExpiresAtBlock{ExpiresAtBlock: 3}},
+	)
+	require.Empty(t, expiredKeys(2, time.Unix(999, 0)))
+
+	// c is made permanent again
+	handleBlock(2, time.Unix(999, 0), &pbkv.KVOperation{Key: "c", Value: []byte("c.2"), Type: pbkv.KVOperation_SET})
+	require.Equal(t, []string{"a", "b"}, expiredKeys(3, time.Unix(1000, 0)))
+	require.Equal(t, []string{"a"}, expiredKeys(3, time.Time{}))
+
+	handleBlock(3, time.Unix(1000, 0))
+	_, err = db.Get(ctx, "a")
+	require.Equal(t, ErrNotFound, err)
+	_, err = db.Get(ctx, "b")
+	require.Equal(t, ErrNotFound, err)
+	value, err := db.Get(ctx, "c")
+	require.NoError(t, err)
+	require.Equal(t, []byte("c.2"), value)
+	require.Empty(t, expiredKeys(4, time.Unix(1100, 0)))
+
+	// undoing block 3 restores the keys along their expiry
+	require.NoError(t, db.HandleBlockUndo(ctx, 2))
+	_, err = db.Flush(ctx, testCursor(2))
+	require.NoError(t, err)
+
+	value, err = db.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("a"), value)
+	require.Equal(t, []string{"a", "b"}, expiredKeys(3, time.Unix(1000, 0)))
+
+	// keys written since the last flush expire too
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "a", Value: []byte("a.3"), Type: pbkv.KVOperation_SET},
+			{Key: "d", Value: []byte("d"), Type: pbkv.KVOperation_SET, Expiry: &pbkv.KVOperation_ExpiresAtBlock{ExpiresAtBlock: 4}},
+		},
+	}))
+	require.Equal(t, []string{"b", "d"}, expiredKeys(4, time.Unix(1100, 0)))
+}
+
+func TestDB_Indexes(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-indexes"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test12")
+
+	transferType := testTransferType(t)
+	_, err := NewIndex("by_unknown", "t:", transferType, "unknown")
+	require.Error(t, err)
+	_, err = NewIndex("by_from", "t:", transferType, "from.name")
+	require.Error(t, err)
+
+	byFrom, err := NewIndex("by_from", "t:", transferType, "from")
+	require.NoError(t, err)
+	byTag, err := NewIndex("by_tag", "t:", transferType, "tags")
+	require.NoError(t, err)
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithIndexes(byFrom, byTag))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	transfer := func(from string, tags ...string) []byte {
+		message := dynamicpb.NewMessage(transferType)
+		message.Set(transferType.Fields().ByName("from"), protoreflect.ValueOfString(from))
+		list := message.Mutable(transferType.Fields().ByName("tags")).List()
+		for _, tag := range tags {
+			list.Append(protoreflect.ValueOfString(tag))
+		}
+		data, err := proto.Marshal(message)
+		require.NoError(t, err)
+		return data
+	}
+	queryIndex := func(index, value string) []string {
+		keys, _, err := db.QueryIndex(ctx, index, value, 0)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		require.NoError(t, err)
+		return keys
+	}
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(1), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "t:1", Value: transfer("alice", "x", "y"), Type: pbkv.KVOperation_SET},
+			{Key: "t:2", Value: transfer("bob"), Type: pbkv.KVOperation_SET},
+			{Key: "other:1", Value: transfer("alice"), Type: pbkv.KVOperation_SET},
+			{Key: "t:invalid", Value: []byte{0xFF}, Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(1))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"t:1"}, queryIndex("by_from", "alice"))
+	require.Equal(t, []string{"t:2"}, queryIndex("by_from", "bob"))
+	require.Equal(t, []string{"t:1"}, queryIndex("by_tag", "y"))
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(2), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "t:1", Value: transfer("bob", "x"), Type: pbkv.KVOperation_SET},
+			{Key: "t:", Type: pbkv.KVOperation_DELETE_PREFIX, Ordinal: 0},
+			{Key: "t:3", Value: transfer("carol"), Type: pbkv.KVOperation_SET, Ordinal: 1},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(2))
+	require.NoError(t, err)
+
+	require.Nil(t, queryIndex("by_from", "alice"))
+	require.Nil(t, queryIndex("by_from", "bob"))
+	require.Nil(t, queryIndex("by_tag", "x"))
+	require.Equal(t, []string{"t:3"}, queryIndex("by_from", "carol"))
+
+	require.NoError(t, db.HandleOperations(ctx, testBlock(3), 0, bstream.StepNew, &pbkv.KVOperations{
+		Operations: []*pbkv.KVOperation{
+			{Key: "t:3", Value: transfer("bob", "x"), Type: pbkv.KVOperation_SET},
+		},
+	}))
+	_, err = db.Flush(ctx, testCursor(3))
+	require.NoError(t, err)
+	require.Equal(t, []string{"t:3"}, queryIndex("by_from", "bob"))
+
+	// undoing blocks restores the entries of the restored values
+	require.NoError(t, db.HandleBlockUndo(ctx, 1))
+	_, err = db.Flush(ctx, testCursor(1))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"t:1"}, queryIndex("by_from", "alice"))
+	require.Equal(t, []string{"t:2"}, queryIndex("by_from", "bob"))
+	require.Equal(t, []string{"t:1"}, queryIndex("by_tag", "x"))
+	require.Nil(t, queryIndex("by_from", "carol"))
+
+	_, _, err = db.QueryIndex(ctx, "by_unknown", "alice", 0)
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+}
+
+func testTransferType(t *testing.T) protoreflect.MessageDescriptor {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test/v1/transfer.proto"),
+		Package: proto.String("test.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Transfer"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("from"), JsonName: proto.String("from"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("tags"), JsonName: proto.String("tags"), Number: proto.Int32(2), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	return file.Messages().Get(0)
+}
+
+func TestDB_Pagination(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-pagination"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test13")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithVersionedKeys(0))
+	require.NoError(t, err)
+	defer db.store.Close()
+	db.store = &reversibleStore{KVStore: db.store}
+
+	var ops []*pbkv.KVOperation
+	for _, key := range []string{"a", "a\x00", "a/1", "a/2", "a/3", "b"} {
+		ops = append(ops, &pbkv.KVOperation{Key: key, Value: []byte(key), Type: pbkv.KVOperation_SET})
+	}
+	require.NoError(t, db.HandleOperations(ctx, testBlock(1), 0, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	keys := func(values []*pbkv.KV) (out []string) {
+		for _, kv := range values {
+			out = append(out, kv.Key)
+		}
+		return out
+	}
+
+	cases := []struct {
+		name         string
+		opts         []ReadOption
+		reverse      bool
+		expectScan   [][]string
+		expectPrefix [][]string
+	}{
+		{"forward", nil, false, [][]string{{"a", "a\x00"}, {"a/1", "a/2"}, {"a/3"}}, [][]string{{"a/1", "a/2"}, {"a/3"}}},
+		{"forward at block", []ReadOption{AtBlock(1)}, false, [][]string{{"a", "a\x00"}, {"a/1", "a/2"}, {"a/3"}}, [][]string{{"a/1", "a/2"}, {"a/3"}}},
+		{"reverse", []ReadOption{Reverse()}, true, [][]string{{"a/3", "a/2"}, {"a/1", "a\x00"}, {"a"}}, [][]string{{"a/3", "a/2"}, {"a/1"}}},
+		{"reverse at block", []ReadOption{AtBlock(1), Reverse()}, true, [][]string{{"a/3", "a/2"}, {"a/1", "a\x00"}, {"a"}}, [][]string{{"a/3", "a/2"}, {"a/1"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var pages [][]string
+			token := ""
+			for {
+				values, limitReached, err := db.Scan(ctx, "a", "b", 2, append(c.opts, PageToken(token))...)
+				require.NoError(t, err)
+				pages = append(pages, keys(values))
+				if token = NextScanPageToken("a", "b", c.reverse, values, limitReached); token == "" {
+					break
+				}
+			}
+			require.Equal(t, c.expectScan, pages)
+
+			pages, token = nil, ""
+			for {
+				values, limitReached, err := db.GetByPrefix(ctx, "a/", 2, append(c.opts, PageToken(token))...)
+				require.NoError(t, err)
+				pages = append(pages, keys(values))
+				if token = NextPrefixPageToken("a/", c.reverse, values, limitReached); token == "" {
+					break
+				}
+			}
+			require.Equal(t, c.expectPrefix, pages)
+		})
+	}
+
+	// open-ended reverse scans start at the last user key, not among internal keys
+	values, limitReached, err := db.Scan(ctx, "", "", 2, Reverse())
+	require.NoError(t, err)
+	require.True(t, limitReached)
+	require.Equal(t, []string{"b", "a/3"}, keys(values))
+
+	values, _, err = db.Scan(ctx, "", "", 2, Reverse(), AtBlock(1))
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a/3"}, keys(values))
+
+	values, limitReached, err = db.Scan(ctx, "", "", 10, Reverse())
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, []string{"b", "a/3", "a/2", "a/1", "a\x00", "a"}, keys(values))
+
+	// tokens only resume the query they were returned for
+	values, limitReached, err = db.Scan(ctx, "a", "b", 2)
+	require.NoError(t, err)
+	scanToken := NextScanPageToken("a", "b", false, values, limitReached)
+
+	_, _, err = db.Scan(ctx, "a", "c", 2, PageToken(scanToken))
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+	_, _, err = db.Scan(ctx, "a", "b", 2, PageToken(scanToken), Reverse())
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+	_, _, err = db.GetByPrefix(ctx, "a", 2, PageToken(scanToken))
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+	_, _, err = db.Scan(ctx, "a", "b", 2, PageToken("not a token"))
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+}
+
+func testBlock(blockNum uint64) bstream.BlockRef {
+	return bstream.NewBlockRef(fmt.Sprintf("block-%d", blockNum), blockNum)
+}
+
+func testCursor(blockNum uint64) *sink.Cursor {
+	block := testBlock(blockNum)
+	return &sink.Cursor{Cursor: &bstream.Cursor{Step: bstream.StepNew, Block: block, LIB: block, HeadBlock: block}}
+}
+
+func changeLogEntries(entries []*pbkv.ChangeLogEntry) (out []string) {
+	value := func(v []byte) string {
+		if v == nil {
+			return "<nil>"
+		}
+		return string(v)
+	}
+
+	for _, entry := range entries {
+		line := fmt.Sprintf("%d@%d %s %s %s -> %s", entry.Sequence, entry.BlockNumber, entry.Type, entry.Key, value(entry.OldValue), value(entry.NewValue))
+		if entry.Undo {
+			line += " (undo)"
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestDB_KeysOnlyAndCount(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-keys-only"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test14")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 2, zap.NewNop(), tracer, WithVersionedKeys(0), WithQueryKeysLimit(4))
+	require.NoError(t, err)
+	defer db.store.Close()
+	db.store = &reversibleStore{KVStore: db.store}
+
+	var ops []*pbkv.KVOperation
+	for _, key := range []string{"a/1", "a/2", "a/3", "b/1", "b/2", "b/3", "b/4", "b/5", "c"} {
+		ops = append(ops, &pbkv.KVOperation{Key: key, Value: []byte(key), Type: pbkv.KVOperation_SET})
+	}
+	require.NoError(t, db.HandleOperations(ctx, testBlock(1), 0, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+	_, err = db.Flush(ctx, nil)
+	require.NoError(t, err)
+
+	// keys only reads drop values and are bounded by the query keys limit
+	values, limitReached, err := db.GetByPrefix(ctx, "a/", 0, KeysOnly())
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, []*pbkv.KV{{Key: "a/1"}, {Key: "a/2"}, {Key: "a/3"}}, values)
+
+	values, limitReached, err = db.Scan(ctx, "b/", "", 4, KeysOnly(), Reverse())
+	require.NoError(t, err)
+	require.True(t, limitReached)
+	require.Equal(t, []*pbkv.KV{{Key: "c"}, {Key: "b/5"}, {Key: "b/4"}, {Key: "b/3"}}, values)
+
+	values, _, err = db.Scan(ctx, "a/", "b/", 0, KeysOnly(), AtBlock(1))
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.KV{{Key: "a/1"}, {Key: "a/2"}, {Key: "a/3"}}, values)
+
+	_, _, err = db.Scan(ctx, "a/", "", 3)
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+	_, _, err = db.Scan(ctx, "a/", "", 5, KeysOnly())
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+
+	count, limitReached, err := db.CountPrefix(ctx, "a/")
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, uint64(3), count)
+
+	count, limitReached, err = db.CountPrefix(ctx, "b/")
+	require.NoError(t, err)
+	require.True(t, limitReached)
+	require.Equal(t, uint64(4), count)
+
+	count, limitReached, err = db.Count(ctx, "a/2", "b/2")
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, uint64(3), count)
+
+	count, limitReached, err = db.Count(ctx, "b/5", "")
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, uint64(2), count)
+
+	count, _, err = db.CountPrefix(ctx, "d")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), count)
+
+	_, _, err = db.CountPrefix(ctx, "")
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+}
+
+func TestDB_CommittedBlock(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-committed-block"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test15")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	block, err := db.CommittedBlock(ctx)
+	require.NoError(t, err)
+	require.Nil(t, block)
+
+	cursor := &sink.Cursor{Cursor: &bstream.Cursor{
+		Step:      bstream.StepNew,
+		Block:     bstream.NewBlockRef("block-5", 5),
+		LIB:       bstream.NewBlockRef("block-3", 3),
+		HeadBlock: bstream.NewBlockRef("block-5", 5),
+	}}
+	require.NoError(t, db.HandleOperations(ctx, testBlock(5), 3, bstream.StepNew, &pbkv.KVOperations{Operations: []*pbkv.KVOperation{
+		{Key: "a", Value: []byte("a"), Type: pbkv.KVOperation_SET},
+	}}))
+	_, err = db.Flush(ctx, cursor)
+	require.NoError(t, err)
+
+	expected := &pbkv.CommittedBlock{BlockNumber: 5, BlockId: "block-5", FinalBlockHeight: 3}
+	block, err = db.CommittedBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected, block)
+
+	// a server running apart from the injector reads it from the store
+	db.committedBlock.Store(nil)
+	block, err = db.CommittedBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected, block)
+
+	require.NoError(t, db.WriteCursor(ctx, testCursor(7)))
+	block, err = db.CommittedBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &pbkv.CommittedBlock{BlockNumber: 7, BlockId: "block-7", FinalBlockHeight: 7}, block)
+}
+
+func TestDB_WaitForBlock(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-wait-for-block"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test16")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	block, err := db.WaitForBlock(timeoutCtx, 1)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Nil(t, block)
+
+	type result struct {
+		block *pbkv.CommittedBlock
+		err   error
+	}
+	done := make(chan result)
+	go func() {
+		block, err := db.WaitForBlock(ctx, 8)
+		done <- result{block, err}
+	}()
+
+	for _, blockNum := range []uint64{5, 8} {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{Operations: []*pbkv.KVOperation{
+			{Key: "a", Value: []byte(fmt.Sprintf("a.%d", blockNum)), Type: pbkv.KVOperation_SET},
+		}}))
+		_, err = db.Flush(ctx, testCursor(blockNum))
+		require.NoError(t, err)
+	}
+
+	select {
+	case r := <-done:
+		require.NoError(t, r.err)
+		require.Equal(t, uint64(8), r.block.BlockNumber)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block 8")
+	}
+
+	// blocks already committed are returned right away
+	block, err = db.WaitForBlock(ctx, 6)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), block.BlockNumber)
+
+	timeoutCtx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	block, err = db.WaitForBlock(timeoutCtx, 9)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, uint64(8), block.BlockNumber)
+}
+
+func TestDB_FinalConsistency(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-final"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test17")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithVersionedKeys(0))
+	require.NoError(t, err)
+	defer db.store.Close()
+	db.store = &reversibleStore{KVStore: db.store}
+
+	set := func(key, value string) *pbkv.KVOperation {
+		return &pbkv.KVOperation{Key: key, Value: []byte(value), Type: pbkv.KVOperation_SET}
+	}
+	flushBlock := func(blockNum, finalBlockHeight uint64, ops ...*pbkv.KVOperation) {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), finalBlockHeight, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+		_, err := db.Flush(ctx, &sink.Cursor{Cursor: &bstream.Cursor{
+			Step:      bstream.StepNew,
+			Block:     bstream.NewBlockRef(fmt.Sprintf("block-%d", blockNum), blockNum),
+			LIB:       bstream.NewBlockRef(fmt.Sprintf("block-%d", finalBlockHeight), finalBlockHeight),
+			HeadBlock: bstream.NewBlockRef(fmt.Sprintf("block-%d", blockNum), blockNum),
+		}})
+		require.NoError(t, err)
+	}
+	keyValues := func(values []*pbkv.KV) (out []string) {
+		for _, kv := range values {
+			out = append(out, kv.Key+"="+string(kv.Value))
+		}
+		return out
+	}
+
+	flushBlock(1, 1, set("k/a", "1"), set("k/b", "1"), set("k/c", "1"), set("z", "1"))
+	flushBlock(2, 1, set("k/a", "2"), &pbkv.KVOperation{Key: "k/b", Type: pbkv.KVOperation_DELETE}, set("k/d", "2"))
+	flushBlock(3, 1, set("k/a", "3"), set("k/b", "3"), set("k/e", "3"))
+
+	value, err := db.Get(ctx, "k/a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("3"), value)
+
+	for key, expected := range map[string]string{"k/a": "1", "k/b": "1", "k/c": "1", "z": "1"} {
+		value, err := db.Get(ctx, key, Final())
+		require.NoError(t, err, key)
+		require.Equal(t, []byte(expected), value, key)
+	}
+	_, err = db.Get(ctx, "k/d", Final())
+	require.Equal(t, ErrNotFound, err)
+	_, err = db.Get(ctx, "k/e", Final())
+	require.Equal(t, ErrNotFound, err)
+
+	values, limitReached, err := db.Scan(ctx, "", "", 10, Final())
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, []string{"k/a=1", "k/b=1", "k/c=1", "z=1"}, keyValues(values))
+
+	values, limitReached, err = db.GetByPrefix(ctx, "k/", 10)
+	require.NoError(t, err)
+	require.False(t, limitReached)
+	require.Equal(t, []string{"k/a=3", "k/b=3", "k/c=1", "k/d=2", "k/e=3"}, keyValues(values))
+
+	cases := []struct {
+		name   string
+		opts   []ReadOption
+		expect [][]string
+	}{
+		{"forward", nil, [][]string{{"k/a=1", "k/b=1"}, {"k/c=1"}}},
+		{"reverse", []ReadOption{Reverse()}, [][]string{{"k/c=1", "k/b=1"}, {"k/a=1"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var pages [][]string
+			token := ""
+			for {
+				values, limitReached, err := db.GetByPrefix(ctx, "k/", 2, append(c.opts, Final(), PageToken(token))...)
+				require.NoError(t, err)
+				pages = append(pages, keyValues(values))
+				if token = NextPrefixPageToken("k/", len(c.opts) > 0, values, limitReached); token == "" {
+					break
+				}
+			}
+			require.Equal(t, c.expect, pages)
+		})
+	}
+
+	values, _, err = db.Scan(ctx, "k/b", "k/z", 10, Final(), KeysOnly())
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.KV{{Key: "k/b"}, {Key: "k/c"}}, values)
+
+	_, _, err = db.Scan(ctx, "k/d", "k/z", 10, Final())
+	require.Equal(t, ErrNotFound, err)
+
+	_, err = db.Get(ctx, "k/a", Final(), AtBlock(1))
+	require.Equal(t, ErrInvalidArguments, errors.Unwrap(err))
+
+	// once block 3 is final, only the changes of block 4 are excluded
+	flushBlock(4, 3, set("k/a", "4"), set("k/f", "4"))
+
+	values, _, err = db.GetByPrefix(ctx, "k/", 10, Final())
+	require.NoError(t, err)
+	require.Equal(t, []string{"k/a=3", "k/b=3", "k/c=1", "k/d=2", "k/e=3"}, keyValues(values))
+}
+
+func TestDB_FinalAfterUndo(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-final-undo"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test24")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer)
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	flush := func(blockNum, finalBlockHeight uint64) {
+		_, err := db.Flush(ctx, &sink.Cursor{Cursor: &bstream.Cursor{
+			Step:      bstream.StepNew,
+			Block:     bstream.NewBlockRef(fmt.Sprintf("block-%d", blockNum), blockNum),
+			LIB:       bstream.NewBlockRef(fmt.Sprintf("block-%d", finalBlockHeight), finalBlockHeight),
+			HeadBlock: bstream.NewBlockRef(fmt.Sprintf("block-%d", blockNum), blockNum),
+		}})
+		require.NoError(t, err)
+	}
+	flushBlock := func(blockNum, finalBlockHeight uint64, key, value string) {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), finalBlockHeight, bstream.StepNew, &pbkv.KVOperations{Operations: []*pbkv.KVOperation{
+			{Key: key, Value: []byte(value), Type: pbkv.KVOperation_SET},
+		}}))
+		flush(blockNum, finalBlockHeight)
+	}
+
+	flushBlock(1, 1, "k/a", "1")
+	flushBlock(2, 1, "k/a", "2")
+	flushBlock(3, 1, "k/a", "3")
+
+	// blocks 2 and 3 are reverted, the new fork only replays block 2
+	require.NoError(t, db.HandleBlockUndo(ctx, 1))
+	flush(1, 1)
+	flushBlock(2, 1, "k/b", "2")
+
+	value, err := db.Get(ctx, "k/a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), value)
+
+	value, err = db.Get(ctx, "k/a", Final())
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), value)
+	_, err = db.Get(ctx, "k/b", Final())
+	require.Equal(t, ErrNotFound, err)
+
+	_, err = db.store.Get(ctx, undoKey(3))
+	require.True(t, errors.Is(err, store.ErrNotFound))
+}
+
+func TestDB_ReverseReads(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-reverse"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test25")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithVersionedKeys(0))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	flushBlock := func(blockNum uint64, ops ...*pbkv.KVOperation) {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+		_, err := db.Flush(ctx, nil)
+		require.NoError(t, err)
+	}
+	set := func(key, value string) *pbkv.KVOperation {
+		return &pbkv.KVOperation{Key: key, Value: []byte(value), Type: pbkv.KVOperation_SET}
+	}
+	keyValues := func(values []*pbkv.KV) (out []string) {
+		for _, kv := range values {
+			out = append(out, kv.Key+"="+string(kv.Value))
+		}
+		return out
+	}
+
+	flushBlock(1, set("a", "1"), set("b", "1"), set("c", "1"))
+	flushBlock(2, set("a", "2"), &pbkv.KVOperation{Key: "b", Type: pbkv.KVOperation_DELETE}, set("d", "2"))
+	flushBlock(3, set("a", "3"), set("b", "3"))
+
+	// the store only scans forward
+	_, _, err = db.Scan(ctx, "", "", 2, Reverse())
+	require.True(t, errors.Is(err, ErrInvalidArguments))
+	_, _, err = db.GetByPrefix(ctx, "a", 2, Reverse(), AtBlock(2))
+	require.True(t, errors.Is(err, ErrInvalidArguments))
+
+	db.store = &reversibleStore{KVStore: db.store}
+	cases := []struct {
+		name         string
+		opts         []ReadOption
+		expect       []string
+		limitReached bool
+	}{
+		{"latest", nil, []string{"d=2", "c=1", "b=3"}, true},
+		{"at block 1", []ReadOption{AtBlock(1)}, []string{"c=1", "b=1", "a=1"}, false},
+		{"at block 2", []ReadOption{AtBlock(2)}, []string{"d=2", "c=1", "a=2"}, false},
+		{"at block 3", []ReadOption{AtBlock(3)}, []string{"d=2", "c=1", "b=3"}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			values, limitReached, err := db.Scan(ctx, "", "", 3, append(c.opts, Reverse())...)
+			require.NoError(t, err)
+			require.Equal(t, c.expect, keyValues(values))
+			require.Equal(t, c.limitReached, limitReached)
+		})
+	}
+}
+
+func TestDB_ReadCache(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-db-read-cache"
+
+	_, tracer := logging.PackageLogger("db", "github.com/streamingfast/substreams-sink-kv/db.test18")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	db, err := New(fmt.Sprintf("badger3://%s", dbPath), 10, zap.NewNop(), tracer, WithReadCache(3))
+	require.NoError(t, err)
+	defer db.store.Close()
+
+	set := func(key, value string) *pbkv.KVOperation {
+		return &pbkv.KVOperation{Key: key, Value: []byte(value), Type: pbkv.KVOperation_SET}
+	}
+	flushBlock := func(blockNum uint64, ops ...*pbkv.KVOperation) {
+		require.NoError(t, db.HandleOperations(ctx, testBlock(blockNum), 0, bstream.StepNew, &pbkv.KVOperations{Operations: ops}))
+		_, err := db.Flush(ctx, testCursor(blockNum))
+		require.NoError(t, err)
+	}
+	get := func(key string) string {
+		value, err := db.Get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			return "<not found>"
+		}
+		require.NoError(t, err)
+		return string(value)
+	}
+	cachedKeys := func() (out []string) {
+		for element := db.cache.lru.Front(); element != nil; element = element.Next() {
+			out = append(out, element.Value.(*readCacheEntry).key)
+		}
+		return out
+	}
+
+	flushBlock(1, set("a", "1"), set("b", "1"), set("c", "1"), set("d", "1"))
+
+	require.Equal(t, "1", get("a"))
+	require.Equal(t, "<not found>", get("missing"))
+	require.Equal(t, []string{"missing", "a"}, cachedKeys())
+
+	// hits are served from the cache, the least recently used key being evicted
+	hits := testutil.ToFloat64(ReadCacheHits.Native())
+	require.Equal(t, "1", get("a"))
+	require.Equal(t, "<not found>", get("missing"))
+	require.Equal(t, hits+2, testutil.ToFloat64(ReadCacheHits.Native()))
+	require.Equal(t, "1", get("b"))
+	require.Equal(t, "1", get("c"))
+	require.Equal(t, []string{"c", "b", "missing"}, cachedKeys())
+
+	// flushes drop the keys they write, reverted ones included
+	flushBlock(2, set("b", "2"), set("missing", "2"))
+	require.Equal(t, []string{"c"}, cachedKeys())
+	require.Equal(t, "2", get("b"))
+	require.Equal(t, "2", get("missing"))
+
+	require.NoError(t, db.HandleBlockUndo(ctx, 1))
+	_, err = db.Flush(ctx, testCursor(1))
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, cachedKeys())
+	require.Equal(t, "1", get("b"))
+	require.Equal(t, "<not found>", get("missing"))
+
+	entries, err := db.GetMany(ctx, []string{"d", "b", "missing", "e"})
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.GetManyEntry{
+		{Key: "d", Value: []byte("1"), Found: true},
+		{Key: "b", Value: []byte("1"), Found: true},
+		{Key: "missing"},
+		{Key: "e"},
+	}, entries)
+	require.Equal(t, []string{"e", "d", "missing"}, cachedKeys())
+
+	flushBlock(2, &pbkv.KVOperation{Key: "d", Type: pbkv.KVOperation_DELETE}, set("e", "2"))
+	entries, err = db.GetMany(ctx, []string{"d", "e"})
+	require.NoError(t, err)
+	require.Equal(t, []*pbkv.GetManyEntry{{Key: "d"}, {Key: "e", Value: []byte("2"), Found: true}}, entries)
+
+	// a value read before a flush is not cached after it, being possibly stale
+	generation := db.cache.currentGeneration()
+	flushBlock(3, set("f", "3"))
+	db.cache.put(generation, "f", nil, false)
+	require.Equal(t, "3", get("f"))
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/streamingfast/kvdb/store"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+// Key expiries live under the `xe` keyspace:
+//
+//   - `xek` + key holds the expiry of key, its kind (block or timestamp) followed by
+//     BE(block or timestamp), so that it can be replaced by the next write to key
+//   - `xeb` + BE(block) + key and `xet` + BE(timestamp) + key index the keys by the
+//     block and the timestamp they expire at
+var expiryKeyPrefix = []byte("xek")
+var expiryBlockPrefix = []byte("xeb")
+var expiryTimestampPrefix = []byte("xet")
+
+const (
+	expiryBlock     byte = 'b'
+	expiryTimestamp byte = 't'
+)
+
+// expiriesInUse avoids any expiry lookup for stores never written with an expiry, it
+// is known once an operation carries one or the `xe` keyspace is found non-empty.
+func (db *OperationDB) expiriesInUse(ctx context.Context) (bool, error) {
+	if db.expiries {
+		return true, nil
+	}
+	if db.expiriesChecked {
+		return false, nil
+	}
+
+	itr := db.store.Prefix(ctx, expiryKeyPrefix, 1, store.KeyOnly())
+	for itr.Next() {
+		db.expiries = true
+	}
+	if err := itr.Err(); err != nil {
+		return false, fmt.Errorf("looking for key expiries: %w", err)
+	}
+	db.expiriesChecked = true
+	return db.expiries, nil
+}
+
+// ExpiredOperations returns a DELETE operation for every key expiring at blockNumber
+// or blockTime, a zero blockTime skipping timestamp expiries. They must be handled
+// with the operations of the block, ahead of them, so that undoing the block
+// restores the keys.
+func (db *OperationDB) ExpiredOperations(ctx context.Context, blockNumber uint64, blockTime time.Time) ([]*pbkv.KVOperation, error) {
+	inUse, err := db.expiriesInUse(ctx)
+	if err != nil || !inUse {
+		return nil, err
+	}
+
+	expired := map[string]bool{}
+	collect := func(prefix []byte, at uint64) error {
+		itr := db.store.Scan(ctx, prefix, expiryIndexKey(prefix, at+1, ""), 0, store.KeyOnly())
+		for itr.Next() {
+			key := string(itr.Item().Key[len(prefix)+8:])
+			if db.pendingExpiryOverride(key) {
+				continue
+			}
+			expired[key] = true
+		}
+		return itr.Err()
+	}
+
+	if err := collect(expiryBlockPrefix, blockNumber); err != nil {
+		return nil, fmt.Errorf("scanning keys expiring at block %d: %w", blockNumber, err)
+	}
+	if !blockTime.IsZero() {
+		if err := collect(expiryTimestampPrefix, uint64(blockTime.Unix())); err != nil {
+			return nil, fmt.Errorf("scanning keys expiring at %s: %w", blockTime, err)
+		}
+	}
+
+	// keys written since the last flush are not indexed yet
+	for key, op := range db.pendingOperations {
+		if op.Type != pbkv.KVOperation_SET {
+			continue
+		}
+		switch expiry := op.Expiry.(type) {
+		case *pbkv.KVOperation_ExpiresAtBlock:
+			if expiry.ExpiresAtBlock <= blockNumber {
+				expired[key] = true
+			}
+		case *pbkv.KVOperation_ExpiresAtTimestamp:
+			if !blockTime.IsZero() && expiry.ExpiresAtTimestamp <= uint64(blockTime.Unix()) {
+				expired[key] = true
+			}
+		}
+	}
+
+	operations := make([]*pbkv.KVOperation, 0, len(expired))
+	for key := range expired {
+		operations = append(operations, &pbkv.KVOperation{Type: pbkv.KVOperation_DELETE, Key: key})
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i].Key < operations[j].Key })
+	return operations, nil
+}
+
+// pendingExpiryOverride is true if the stored expiry of key no longer applies because
+// key was written or deleted since the last flush.
+func (db *OperationDB) pendingExpiryOverride(key string) bool {
+	if _, found := db.pendingOperations[key]; found {
+		return true
+	}
+	for _, prefix := range db.pendingPrefixDeletions {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// flushExpiry replaces the stored expiry of key by the one of op, op being nil when
+// key is deleted by a prefix deletion.
+func (db *OperationDB) flushExpiry(ctx context.Context, batch *flushBatch, key string, op *pbkv.KVOperation) error {
+	previous, err := db.storedExpiry(ctx, key)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		batch.Delete(expiryKey(key))
+		batch.Delete(expiryIndexKeyOf(key, previous))
+	}
+
+	if op == nil || op.Type != pbkv.KVOperation_SET || op.Expiry == nil {
+		return nil
+	}
+	batch.Put(expiryKey(key), encodeExpiry(op))
+	batch.Put(expiryIndexKeyOf(key, op), presenceValue)
+	return nil
+}
+
+// storedExpiry returns an operation only carrying the stored expiry of key, nil if key
+// does not expire or expiries are not in use.
+func (db *OperationDB) storedExpiry(ctx context.Context, key string) (*pbkv.KVOperation, error) {
+	inUse, err := db.expiriesInUse(ctx)
+	if err != nil || !inUse {
+		return nil, err
+	}
+
+	value, err := db.store.Get(ctx, expiryKey(key))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting expiry of key %q: %w", key, err)
+	}
+	return decodeExpiry(value)
+}
+
+// withStoredExpiry sets on the restore operation op the expiry key currently has, so
+// that undoing a block restores keys along their expiry.
+func (db *OperationDB) withStoredExpiry(ctx context.Context, op *pbkv.KVOperation) (*pbkv.KVOperation, error) {
+	if op == nil || op.Type != pbkv.KVOperation_SET {
+		return op, nil
+	}
+
+	expiry, err := db.storedExpiry(ctx, op.Key)
+	if err != nil {
+		return nil, err
+	}
+	if expiry != nil {
+		op.Expiry = expiry.Expiry
+	}
+	return op, nil
+}
+
+func encodeExpiry(op *pbkv.KVOperation) []byte {
+	switch expiry := op.Expiry.(type) {
+	case *pbkv.KVOperation_ExpiresAtBlock:
+		return binary.BigEndian.AppendUint64([]byte{expiryBlock}, expiry.ExpiresAtBlock)
+	case *pbkv.KVOperation_ExpiresAtTimestamp:
+		return binary.BigEndian.AppendUint64([]byte{expiryTimestamp}, expiry.ExpiresAtTimestamp)
+	default:
+		panic(fmt.Sprintf("invalid expiry type %T", op.Expiry))
+	}
+}
+
+func decodeExpiry(in []byte) (*pbkv.KVOperation, error) {
+	if len(in) != 9 {
+		return nil, fmt.Errorf("invalid expiry value")
+	}
+
+	at := binary.BigEndian.Uint64(in[1:])
+	switch in[0] {
+	case expiryBlock:
+		return &pbkv.KVOperation{Expiry: &pbkv.KVOperation_ExpiresAtBlock{ExpiresAtBlock: at}}, nil
+	case expiryTimestamp:
+		return &pbkv.KVOperation{Expiry: &pbkv.KVOperation_ExpiresAtTimestamp{ExpiresAtTimestamp: at}}, nil
+	default:
+		return nil, fmt.Errorf("invalid expiry kind %q", in[0])
+	}
+}
+
+func expiryKey(key string) []byte {
+	return append(append([]byte{}, expiryKeyPrefix...), key...)
+}
+
+func expiryIndexKeyOf(key string, op *pbkv.KVOperation) []byte {
+	if expiry, ok := op.Expiry.(*pbkv.KVOperation_ExpiresAtTimestamp); ok {
+		return expiryIndexKey(expiryTimestampPrefix, expiry.ExpiresAtTimestamp, key)
+	}
+	return expiryIndexKey(expiryBlockPrefix, op.GetExpiresAtBlock(), key)
+}
+
+func expiryIndexKey(prefix []byte, at uint64, key string) []byte {
+	out := binary.BigEndian.AppendUint64(append([]byte{}, prefix...), at)
+	return append(out, key...)
+}
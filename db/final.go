@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// finalOverlay maps the keys changed by the blocks above the final block height to the
+// operation restoring the value they had at that height, a SET or a DELETE if they did
+// not exist.
+type finalOverlay map[string]*pbkv.KVOperation
+
+// finalOverlay reads the undo entries of the blocks above the final block height of the
+// committed cursor, all of them if none was committed. They are applied from the
+// highest block down, as HandleBlockUndo does, the lowest block having the last word.
+func (db *OperationDB) finalOverlay(ctx context.Context) (finalOverlay, error) {
+	var finalBlockHeight uint64
+	block, err := db.CommittedBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading committed block: %w", err)
+	}
+	if block != nil {
+		finalBlockHeight = block.FinalBlockHeight
+	}
+
+	overlay := finalOverlay{}
+	itr := db.store.Scan(ctx, undoKey(math.MaxUint64), undoKey(finalBlockHeight), 0)
+	for itr.Next() {
+		undoOperations := &pbkv.KVOperations{}
+		if err := proto.Unmarshal(itr.Item().Value, undoOperations); err != nil {
+			return nil, fmt.Errorf("unmarshaling undo operations: %w", err)
+		}
+		for _, op := range undoOperations.Operations {
+			overlay[op.Key] = op
+		}
+	}
+	if err := itr.Err(); err != nil {
+		return nil, fmt.Errorf("scanning undo operations above block %d: %w", finalBlockHeight, err)
+	}
+	return overlay, nil
+}
+
+// getFinal returns the value of key as of the final block height.
+func (db *OperationDB) getFinal(ctx context.Context, key string) ([]byte, error) {
+	overlay, err := db.finalOverlay(ctx)
+	if err != nil {
+		return nil, err
+	}
+	op, found := overlay[key]
+	if !found {
+		return db.Get(ctx, key)
+	}
+	if op.Type != pbkv.KVOperation_SET {
+		return nil, ErrNotFound
+	}
+	return op.Value, nil
+}
+
+// scanFinal returns the user keys of [start, exclusiveEnd) starting with prefix as of the
+// final block height, an empty exclusiveEnd being open-ended. scanLatest reads the
+// latest values of the range, it is asked for as many more values as there are keys in
+// the overlay, each of them hiding at most one of the latest values.
+func (db *OperationDB) scanFinal(ctx context.Context, prefix, start, exclusiveEnd string, limit int, options *ReadOptions, scanLatest func(limit int) ([]*pbkv.KV, bool, error)) (values []*pbkv.KV, limitReached bool, err error) {
+	overlay, err := db.finalOverlay(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	inRange := func(key string) bool {
+		return key >= start && (exclusiveEnd == "" || key < exclusiveEnd) && strings.HasPrefix(key, prefix)
+	}
+	// before reports whether a comes before b in the order of the query
+	before := func(a, b string) bool {
+		if options.Reverse {
+			return a > b
+		}
+		return a < b
+	}
+
+	var overlaid []*pbkv.KVOperation
+	for key, op := range overlay {
+		if inRange(key) {
+			overlaid = append(overlaid, op)
+		}
+	}
+
+	latest, latestLimitReached, err := scanLatest(limit + len(overlaid))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, false, err
+	}
+
+	for _, kv := range latest {
+		if _, found := overlay[kv.Key]; !found {
+			values = append(values, kv)
+		}
+	}
+	for _, op := range overlaid {
+		if op.Type != pbkv.KVOperation_SET {
+			continue
+		}
+		// past the last latest value of a truncated range, keys not read may come first
+		if latestLimitReached && before(latest[len(latest)-1].Key, op.Key) {
+			continue
+		}
+		values = append(values, options.keyValue(op.Key, op.Value))
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return before(values[i].Key, values[j].Key)
+	})
+
+	if len(values) == 0 {
+		return nil, false, ErrNotFound
+	}
+	if len(values) > limit {
+		return values[:limit], true, nil
+	}
+	return values, latestLimitReached, nil
+}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/streamingfast/kvdb/store"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Index entries live under `xix` + escaped name + 0x00 0x01 + escaped value + 0x00 0x01
+// + key, the escaping being the one of versioned keys, so that the keys having a
+// value in an index are listed by a prefix scan.
+var indexKeyPrefix = []byte("xix")
+
+// Index maps the values stored under KeyPrefix, protobuf messages of type valueType,
+// to their keys through the value of a field.
+type Index struct {
+	Name      string
+	KeyPrefix string
+
+	valueType protoreflect.MessageDescriptor
+	fieldPath []protoreflect.FieldDescriptor
+}
+
+// NewIndex creates an index on field of valueType, nested fields being separated by
+// dots. The indexed field must be a scalar or an enum, repeated or not.
+func NewIndex(name, keyPrefix string, valueType protoreflect.MessageDescriptor, field string) (*Index, error) {
+	if name == "" {
+		return nil, fmt.Errorf("index name must not be empty")
+	}
+
+	index := &Index{Name: name, KeyPrefix: keyPrefix, valueType: valueType}
+	message := valueType
+	parts := strings.Split(field, ".")
+	for i, part := range parts {
+		if message == nil {
+			return nil, fmt.Errorf("field %q of %q is not a message", strings.Join(parts[:i], "."), valueType.FullName())
+		}
+
+		fd := message.Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			return nil, fmt.Errorf("field %q not found in %q", part, message.FullName())
+		}
+		if fd.IsMap() {
+			return nil, fmt.Errorf("map field %q cannot be indexed", part)
+		}
+
+		last := i == len(parts)-1
+		isMessage := fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+		if last && isMessage {
+			return nil, fmt.Errorf("message field %q cannot be indexed, a nested scalar field must be given", part)
+		}
+		if !last && fd.IsList() {
+			return nil, fmt.Errorf("only the last field can be repeated, %q is", part)
+		}
+
+		message = fd.Message()
+		index.fieldPath = append(index.fieldPath, fd)
+	}
+	return index, nil
+}
+
+// values returns the index values of an encoded value, none if value cannot be decoded.
+func (i *Index) values(value []byte) ([]string, error) {
+	message := dynamicpb.NewMessage(i.valueType)
+	if err := proto.Unmarshal(value, message); err != nil {
+		return nil, err
+	}
+
+	var current protoreflect.Message = message
+	for _, fd := range i.fieldPath[:len(i.fieldPath)-1] {
+		if !current.Has(fd) {
+			return nil, nil
+		}
+		current = current.Get(fd).Message()
+	}
+
+	fd := i.fieldPath[len(i.fieldPath)-1]
+	if !fd.IsList() {
+		return []string{formatIndexValue(fd, current.Get(fd))}, nil
+	}
+
+	list := current.Get(fd).List()
+	values := make([]string, 0, list.Len())
+	for j := 0; j < list.Len(); j++ {
+		values = append(values, formatIndexValue(fd, list.Get(j)))
+	}
+	return values, nil
+}
+
+// formatIndexValue is the string form of field values given to QueryIndex: strings as
+// is, bytes hex encoded, numbers in base 10, booleans as true or false and enums by
+// their name.
+func formatIndexValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return value.String()
+	case protoreflect.BytesKind:
+		return hex.EncodeToString(value.Bytes())
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(value.Bool())
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return strconv.FormatInt(int64(value.Enum()), 10)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(value.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(value.Uint(), 10)
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 32)
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 64)
+	default:
+		panic(fmt.Sprintf("invalid index field kind %s", fd.Kind()))
+	}
+}
+
+// flushIndexes replaces the index entries of key, computed from its stored value, by
+// the ones of newValue, nil when key is deleted.
+func (db *OperationDB) flushIndexes(ctx context.Context, batch *flushBatch, key string, newValue []byte) error {
+	var indexes []*Index
+	for _, index := range db.indexes {
+		if strings.HasPrefix(key, index.KeyPrefix) {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	oldValue, err := db.store.Get(ctx, userKey(key))
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("getting previous value of key %q: %w", key, err)
+	}
+	oldFound := err == nil
+
+	for _, index := range indexes {
+		var oldValues, newValues []string
+		if oldFound {
+			oldValues = db.indexValues(index, key, oldValue)
+		}
+		if newValue != nil {
+			newValues = db.indexValues(index, key, newValue)
+		}
+
+		kept := map[string]bool{}
+		for _, value := range newValues {
+			kept[value] = true
+		}
+		for _, value := range oldValues {
+			if !kept[value] {
+				batch.Delete(indexKey(index.Name, value, key))
+			}
+		}
+		for value := range kept {
+			batch.Put(indexKey(index.Name, value, key), presenceValue)
+		}
+	}
+	return nil
+}
+
+// indexValues returns the values of index for the value of key, values that cannot be
+// decoded are not indexed.
+func (db *OperationDB) indexValues(index *Index, key string, value []byte) []string {
+	values, err := index.values(value)
+	if err != nil {
+		db.logger.Warn("value cannot be decoded, it is not indexed", zap.String("index", index.Name), zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return values
+}
+
+func (db *OperationDB) QueryIndex(ctx context.Context, index string, value string, limit int) (keys []string, limitReached bool, err error) {
+	if limit == 0 {
+		limit = db.QueryRowsLimit
+	}
+	if limit < 0 || limit > db.QueryRowsLimit {
+		return nil, false, fmt.Errorf("%w: request value for 'limit' must be between 1 and %d, but received %d", ErrInvalidArguments, db.QueryRowsLimit, limit)
+	}
+	if _, found := db.indexes[index]; !found {
+		return nil, false, fmt.Errorf("%w: request value for 'index' must be one of the indexes declared in the sink config, but received %q", ErrInvalidArguments, index)
+	}
+
+	prefix := indexValuePrefix(index, value)
+	itr := db.store.Prefix(ctx, prefix, limit+1, store.KeyOnly())
+	for itr.Next() {
+		if len(keys) == limit {
+			limitReached = true
+			break
+		}
+		keys = append(keys, string(itr.Item().Key[len(prefix):]))
+	}
+	if err := itr.Err(); err != nil {
+		return nil, false, err
+	}
+	if len(keys) == 0 {
+		return nil, false, ErrNotFound
+	}
+	return keys, limitReached, nil
+}
+
+func indexValuePrefix(index string, value string) []byte {
+	out := append([]byte{}, indexKeyPrefix...)
+	out = append(append(out, escapeVersionedKey(index)...), 0x00, 0x01)
+	return append(append(out, escapeVersionedKey(value)...), 0x00, 0x01)
+}
+
+func indexKey(index string, value string, key string) []byte {
+	return append(indexValuePrefix(index, value), key...)
+}
@@ -1,9 +1,11 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
+	"github.com/streamingfast/kvdb/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/test-go/testify/require"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -33,3 +35,39 @@ func assertProtoEqual(t *testing.T, expected proto.Message, actual proto.Message
 		assert.Equal(t, expectedAsMap, actualAsMap)
 	}
 }
+
+// reversibleStore adds reverse scans to stores only able to scan forward, reading the
+// whole range forward.
+type reversibleStore struct {
+	store.KVStore
+}
+
+func (s *reversibleStore) ReverseScan(ctx context.Context, start, exclusiveEnd []byte, limit int) *store.Iterator {
+	var items []store.KV
+	itr := s.Scan(ctx, start, exclusiveEnd, 0)
+	for itr.Next() {
+		items = append(items, itr.Item())
+	}
+
+	out := store.NewIterator(ctx)
+	go func() {
+		if err := itr.Err(); err != nil {
+			out.PushError(err)
+			return
+		}
+		for i := len(items) - 1; i >= 0; i-- {
+			if limit > 0 && len(items)-i > limit {
+				break
+			}
+			if !out.PushItem(items[i]) {
+				return
+			}
+		}
+		out.PushFinished()
+	}()
+	return out
+}
+
+func (s *reversibleStore) ReversePrefix(ctx context.Context, prefix []byte, limit int) *store.Iterator {
+	return s.ReverseScan(ctx, prefix, store.Key(prefix).PrefixNext(), limit)
+}
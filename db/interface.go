@@ -7,8 +7,19 @@ import (
 )
 
 type Reader interface {
-	Get(ctx context.Context, key string) (value []byte, err error)
-	GetMany(ctx context.Context, keys []string) (values [][]byte, err error)
-	GetByPrefix(ctx context.Context, prefix string, limit int) (values []*kvv1.KV, limitReached bool, err error)
-	Scan(ctx context.Context, start string, exclusiveEnd string, limit int) (values []*kvv1.KV, limitReached bool, err error)
+	Get(ctx context.Context, key string, opts ...ReadOption) (value []byte, err error)
+	GetMany(ctx context.Context, keys []string) (entries []*kvv1.GetManyEntry, err error)
+	GetByPrefix(ctx context.Context, prefix string, limit int, opts ...ReadOption) (values []*kvv1.KV, limitReached bool, err error)
+	Scan(ctx context.Context, start string, exclusiveEnd string, limit int, opts ...ReadOption) (values []*kvv1.KV, limitReached bool, err error)
+	Count(ctx context.Context, begin string, exclusiveEnd string) (count uint64, limitReached bool, err error)
+	CountPrefix(ctx context.Context, prefix string) (count uint64, limitReached bool, err error)
+	QueryIndex(ctx context.Context, index string, value string, limit int) (keys []string, limitReached bool, err error)
+	ReadChanges(ctx context.Context, fromSequence uint64, limit int) (entries []*kvv1.ChangeLogEntry, nextSequence uint64, limitReached bool, err error)
+	CommittedBlock(ctx context.Context) (block *kvv1.CommittedBlock, err error)
+}
+
+// Watcher is implemented by stores that notify of the changes they commit.
+type Watcher interface {
+	Subscribe(bufferSize int) *Subscription
+	WaitForBlock(ctx context.Context, blockNum uint64) (block *kvv1.CommittedBlock, err error)
 }
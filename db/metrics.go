@@ -0,0 +1,12 @@
+package db
+
+import "github.com/streamingfast/dmetrics"
+
+func RegisterMetrics() {
+	metrics.Register()
+}
+
+var metrics = dmetrics.NewSet()
+
+var ReadCacheHits = metrics.NewCounter("substreams_sink_kv_read_cache_hits", "The number of keys read through Get and GetMany found in the read cache")
+var ReadCacheMisses = metrics.NewCounter("substreams_sink_kv_read_cache_misses", "The number of keys read through Get and GetMany missing from the read cache")
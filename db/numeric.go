@@ -0,0 +1,97 @@
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+var ErrNumericOverflow = errors.New("numeric overflow")
+
+// numericDelta returns the delta to apply for an ADD or INCREMENT operation. Arithmetic
+// is done on big integers so that every encoding shares the same overflow checks.
+func numericDelta(op *pbkv.KVOperation) (*big.Int, error) {
+	if op.Type == pbkv.KVOperation_INCREMENT {
+		return big.NewInt(1), nil
+	}
+
+	switch op.NumericEncoding {
+	case pbkv.KVOperation_INT64_BIG_ENDIAN, pbkv.KVOperation_UINT64_BIG_ENDIAN:
+		if len(op.Value) != 8 {
+			return nil, fmt.Errorf("delta must be 8 bytes, got %d", len(op.Value))
+		}
+		return big.NewInt(int64(binary.BigEndian.Uint64(op.Value))), nil
+	case pbkv.KVOperation_DECIMAL_STRING:
+		delta, ok := new(big.Int).SetString(string(op.Value), 10)
+		if !ok {
+			return nil, fmt.Errorf("delta %q is not a base 10 integer", string(op.Value))
+		}
+		return delta, nil
+	default:
+		return nil, fmt.Errorf("invalid numeric encoding %s", op.NumericEncoding)
+	}
+}
+
+// applyNumericOperation computes the new value of key once the ADD or INCREMENT
+// operation is applied to current, a missing value counting as zero.
+func applyNumericOperation(op *pbkv.KVOperation, current []byte, found bool) ([]byte, error) {
+	delta, err := numericDelta(op)
+	if err != nil {
+		return nil, err
+	}
+
+	value := new(big.Int)
+	if found {
+		value, err = decodeNumeric(op.NumericEncoding, current)
+		if err != nil {
+			return nil, fmt.Errorf("current value: %w", err)
+		}
+	}
+
+	return encodeNumeric(op.NumericEncoding, value.Add(value, delta))
+}
+
+func decodeNumeric(encoding pbkv.KVOperation_NumericEncoding, in []byte) (*big.Int, error) {
+	switch encoding {
+	case pbkv.KVOperation_INT64_BIG_ENDIAN:
+		if len(in) != 8 {
+			return nil, fmt.Errorf("int64 value must be 8 bytes, got %d", len(in))
+		}
+		return big.NewInt(int64(binary.BigEndian.Uint64(in))), nil
+	case pbkv.KVOperation_UINT64_BIG_ENDIAN:
+		if len(in) != 8 {
+			return nil, fmt.Errorf("uint64 value must be 8 bytes, got %d", len(in))
+		}
+		return new(big.Int).SetUint64(binary.BigEndian.Uint64(in)), nil
+	case pbkv.KVOperation_DECIMAL_STRING:
+		out, ok := new(big.Int).SetString(string(in), 10)
+		if !ok {
+			return nil, fmt.Errorf("value %q is not a base 10 integer", string(in))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("invalid numeric encoding %s", encoding)
+	}
+}
+
+func encodeNumeric(encoding pbkv.KVOperation_NumericEncoding, value *big.Int) ([]byte, error) {
+	switch encoding {
+	case pbkv.KVOperation_INT64_BIG_ENDIAN:
+		if !value.IsInt64() {
+			return nil, fmt.Errorf("%w: %s does not fit in an int64", ErrNumericOverflow, value)
+		}
+		return binary.BigEndian.AppendUint64(nil, uint64(value.Int64())), nil
+	case pbkv.KVOperation_UINT64_BIG_ENDIAN:
+		if !value.IsUint64() {
+			return nil, fmt.Errorf("%w: %s does not fit in an uint64", ErrNumericOverflow, value)
+		}
+		return binary.BigEndian.AppendUint64(nil, value.Uint64()), nil
+	case pbkv.KVOperation_DECIMAL_STRING:
+		return []byte(value.String()), nil
+	default:
+		return nil, fmt.Errorf("invalid numeric encoding %s", encoding)
+	}
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/streamingfast/kvdb/store"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+// Option configures the OperationDB, see New.
+type Option interface {
+	apply(db *OperationDB)
+}
+
+type versionedKeysOpt struct {
+	retentionBlocks uint64
+}
+
+// WithVersionedKeys makes every SET and DELETE to also be stored along the block number
+// it happened at, enabling reads at a past block through AtBlock. Versions older than
+// retentionBlocks below the final block height are purged, 0 keeps them all.
+func WithVersionedKeys(retentionBlocks uint64) Option {
+	return versionedKeysOpt{retentionBlocks: retentionBlocks}
+}
+
+func (o versionedKeysOpt) apply(db *OperationDB) {
+	db.versions = newVersions(o.retentionBlocks)
+}
+
+type changeLogOpt struct {
+	retentionEntries uint64
+	retentionBlocks  uint64
+}
+
+// WithChangeLog makes every flush to also append the changes it applies, reverted ones
+// included, to an ordered change log read through ReadChanges. Entries beyond the last
+// retentionEntries ones, or older than retentionBlocks below the flushed block, are
+// purged, 0 disabling the corresponding bound.
+func WithChangeLog(retentionEntries, retentionBlocks uint64) Option {
+	return changeLogOpt{retentionEntries: retentionEntries, retentionBlocks: retentionBlocks}
+}
+
+func (o changeLogOpt) apply(db *OperationDB) {
+	db.changeLog = newChangeLog(o.retentionEntries, o.retentionBlocks)
+}
+
+type queryKeysLimitOpt int
+
+// WithQueryKeysLimit sets the limit of keys only reads and counts, which do not transfer
+// values and can be bounded higher than the query rows limit, the default.
+func WithQueryKeysLimit(limit int) Option {
+	return queryKeysLimitOpt(limit)
+}
+
+func (o queryKeysLimitOpt) apply(db *OperationDB) {
+	db.QueryKeysLimit = int(o)
+}
+
+type indexesOpt []*Index
+
+// WithIndexes makes flushes to maintain the entries of indexes, queried through
+// QueryIndex. Index names must be unique.
+func WithIndexes(indexes ...*Index) Option {
+	return indexesOpt(indexes)
+}
+
+func (o indexesOpt) apply(db *OperationDB) {
+	for _, index := range o {
+		db.indexes[index.Name] = index
+	}
+}
+
+type readCacheOpt int
+
+// WithReadCache keeps the latest values of the last size keys read through Get and
+// GetMany in memory. Flushes must all go through this instance, which invalidates the
+// keys they write, the cache being otherwise stale.
+func WithReadCache(size int) Option {
+	return readCacheOpt(size)
+}
+
+func (o readCacheOpt) apply(db *OperationDB) {
+	if o > 0 {
+		db.cache = newReadCache(int(o))
+	}
+}
+
+func NewReadOptions(opts ...ReadOption) *ReadOptions {
+	out := &ReadOptions{}
+	for _, opt := range opts {
+		opt.Apply(out)
+	}
+	return out
+}
+
+// ReadOptions are the options of a Reader query, the zero value reads the latest
+// value of keys.
+type ReadOptions struct {
+	AtBlock   *uint64
+	PageToken string
+	Reverse   bool
+	KeysOnly  bool
+	Final     bool
+}
+
+type ReadOption interface {
+	Apply(o *ReadOptions)
+}
+
+// AtBlock reads the values keys had once block blockNum was applied, it requires the
+// store to be written with versioned keys.
+func AtBlock(blockNum uint64) ReadOption {
+	return atBlockReadOption(blockNum)
+}
+
+type atBlockReadOption uint64
+
+func (o atBlockReadOption) Apply(opts *ReadOptions) {
+	blockNum := uint64(o)
+	opts.AtBlock = &blockNum
+}
+
+// PageToken resumes a Scan or GetByPrefix after the last key of a previous page, token
+// being the one returned by NextScanPageToken or NextPrefixPageToken for the same bounds.
+func PageToken(token string) ReadOption {
+	return pageTokenReadOption(token)
+}
+
+type pageTokenReadOption string
+
+func (o pageTokenReadOption) Apply(opts *ReadOptions) {
+	opts.PageToken = string(o)
+}
+
+// Reverse makes Scan and GetByPrefix return keys from the end of their range backward,
+// page tokens of reverse queries resuming before the last key of the previous page.
+func Reverse() ReadOption {
+	return reverseReadOption{}
+}
+
+type reverseReadOption struct{}
+
+func (o reverseReadOption) Apply(opts *ReadOptions) {
+	opts.Reverse = true
+}
+
+// KeysOnly makes Scan and GetByPrefix return keys without their value, their limit being
+// the query keys limit, see WithQueryKeysLimit.
+func KeysOnly() ReadOption {
+	return keysOnlyReadOption{}
+}
+
+type keysOnlyReadOption struct{}
+
+func (o keysOnlyReadOption) Apply(opts *ReadOptions) {
+	opts.KeysOnly = true
+}
+
+// Final makes Get, Scan and GetByPrefix read the values as of the final block height of
+// the committed cursor, excluding the changes of the blocks that may still be undone.
+func Final() ReadOption {
+	return finalReadOption{}
+}
+
+type finalReadOption struct{}
+
+func (o finalReadOption) Apply(opts *ReadOptions) {
+	opts.Final = true
+}
+
+func (o *ReadOptions) validate() error {
+	if o.Final && o.AtBlock != nil {
+		return fmt.Errorf("%w: request value for 'at_block' cannot be combined with the FINAL 'consistency'", ErrInvalidArguments)
+	}
+	return nil
+}
+
+func (o *ReadOptions) storeOptions() []store.ReadOption {
+	if o.KeysOnly {
+		return []store.ReadOption{store.KeyOnly()}
+	}
+	return nil
+}
+
+func (o *ReadOptions) keyValue(key string, value []byte) *pbkv.KV {
+	if o.KeysOnly {
+		return &pbkv.KV{Key: key}
+	}
+	return &pbkv.KV{Key: key, Value: value}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+// Page tokens are base64 (URL alphabet) encoded, a version byte followed by the kind of
+// query, `s` for Scan or `p` for GetByPrefix, its direction, `f` for forward or `r` for
+// reverse, and the uvarint length prefixed bounds of the query and last key returned.
+// They are opaque to clients, which must send them along the same request they were
+// returned for.
+const pageTokenVersion byte = 1
+
+const (
+	pageTokenScan   byte = 's'
+	pageTokenPrefix byte = 'p'
+
+	pageTokenForward byte = 'f'
+	pageTokenReverse byte = 'r'
+)
+
+type pageToken struct {
+	kind    byte
+	reverse bool
+	bounds  []string
+	lastKey string
+}
+
+// NextScanPageToken returns the page token resuming the Scan of [begin, exclusiveEnd),
+// in reverse order or not, after values, empty when there is no next page.
+func NextScanPageToken(begin, exclusiveEnd string, reverse bool, values []*pbkv.KV, limitReached bool) string {
+	if !limitReached || len(values) == 0 {
+		return ""
+	}
+	return (&pageToken{kind: pageTokenScan, reverse: reverse, bounds: []string{begin, exclusiveEnd}, lastKey: values[len(values)-1].Key}).encode()
+}
+
+// NextPrefixPageToken returns the page token resuming the GetByPrefix of prefix, in
+// reverse order or not, after values, empty when there is no next page.
+func NextPrefixPageToken(prefix string, reverse bool, values []*pbkv.KV, limitReached bool) string {
+	if !limitReached || len(values) == 0 {
+		return ""
+	}
+	return (&pageToken{kind: pageTokenPrefix, reverse: reverse, bounds: []string{prefix}, lastKey: values[len(values)-1].Key}).encode()
+}
+
+func (t *pageToken) encode() string {
+	out := []byte{pageTokenVersion, t.kind, pageTokenDirection(t.reverse)}
+	for _, value := range append(t.bounds, t.lastKey) {
+		out = binary.AppendUvarint(out, uint64(len(value)))
+		out = append(out, value...)
+	}
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+// resumeAfter decodes token, returning the last key of the previous page. It is an
+// ErrInvalidArguments error if token is malformed or was returned for another query.
+func resumeAfter(token string, kind byte, reverse bool, bounds ...string) (string, error) {
+	invalid := fmt.Errorf("%w: request value for 'page_token' must be the 'next_page_token' of a previous response to the same request", ErrInvalidArguments)
+
+	in, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(in) < 3 || in[0] != pageTokenVersion || in[1] != kind || in[2] != pageTokenDirection(reverse) {
+		return "", invalid
+	}
+
+	in = in[3:]
+	values := make([]string, 0, len(bounds)+1)
+	for len(in) > 0 {
+		length, n := binary.Uvarint(in)
+		if n <= 0 || uint64(len(in)-n) < length {
+			return "", invalid
+		}
+		values = append(values, string(in[n:n+int(length)]))
+		in = in[n+int(length):]
+	}
+
+	if len(values) != len(bounds)+1 {
+		return "", invalid
+	}
+	for i, bound := range bounds {
+		if values[i] != bound {
+			return "", invalid
+		}
+	}
+	return values[len(bounds)], nil
+}
+
+func pageTokenDirection(reverse bool) byte {
+	if reverse {
+		return pageTokenReverse
+	}
+	return pageTokenForward
+}
+
+// keyAfter is the smallest key greater than key.
+func keyAfter(key string) string {
+	return key + "\x00"
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/streamingfast/kvdb/store"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"go.uber.org/zap"
+)
+
+// reversibleStore returns the store when it implements store.ReversibleKVStore, reverse
+// reads being refused on stores only able to scan forward.
+func (db *OperationDB) reversibleStore() (store.ReversibleKVStore, error) {
+	reversible, ok := db.store.(store.ReversibleKVStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: reverse reads are not supported by this store", ErrInvalidArguments)
+	}
+	return reversible, nil
+}
+
+// reverseScan returns the user keys in [start, exclusiveEnd) from the end of the range
+// backward.
+func (db *OperationDB) reverseScan(ctx context.Context, start, exclusiveEnd []byte, limit int, options *ReadOptions) (values []*pbkv.KV, limitReached bool, err error) {
+	reversible, err := db.reversibleStore()
+	if err != nil {
+		return nil, false, err
+	}
+
+	itr := reversible.ReverseScan(ctx, start, exclusiveEnd, limit+1)
+	for itr.Next() {
+		if !isUserKey(itr.Item().Key) {
+			db.logger.Debug("skipping non-user-key", zap.String("key", string(itr.Item().Key)))
+			continue
+		}
+		if len(values) == limit {
+			limitReached = true
+			break
+		}
+		values = append(values, options.keyValue(fromUserKey(itr.Item().Key), itr.Item().Value))
+	}
+	if err := itr.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(values) == 0 {
+		return nil, false, ErrNotFound
+	}
+	return values, limitReached, nil
+}
+
+// reverseScanAt returns the values at atBlock of the version keys in [start,
+// exclusiveEnd) from the end of the range backward, walking the range one key at a
+// time until limit+1 keys having a value at atBlock are found.
+func (db *OperationDB) reverseScanAt(ctx context.Context, start, exclusiveEnd []byte, atBlock uint64, limit int, options *ReadOptions) (values []*pbkv.KV, limitReached bool, err error) {
+	reversible, err := db.reversibleStore()
+	if err != nil {
+		return nil, false, err
+	}
+
+	for {
+		// the versions of a key come from the oldest backward, the first one read
+		// names the previous key of the range
+		it, found, err := firstItem(reversible.ReverseScan(ctx, start, exclusiveEnd, 1))
+		if err != nil {
+			return nil, false, err
+		}
+		if !found {
+			break
+		}
+		key, _, err := decodeVersionKey(it.Key)
+		if err != nil {
+			return nil, false, err
+		}
+
+		version, err := db.versionAt(ctx, key, atBlock)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(version) > 0 && version[0] == versionSet {
+			if len(values) == limit {
+				limitReached = true
+				break
+			}
+			values = append(values, options.keyValue(key, version[1:]))
+		}
+		exclusiveEnd = versionKey(key, math.MaxUint64)
+	}
+
+	if len(values) == 0 {
+		return nil, false, ErrNotFound
+	}
+	return values, limitReached, nil
+}
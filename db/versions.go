@@ -0,0 +1,500 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/streamingfast/kvdb/store"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+// Versioned keys live under the `xv` keyspace:
+//
+//   - `xvk` + escaped key + BE(MaxUint64-block) holds the value key had once block
+//     was applied, so the versions of a key sort from the newest to the oldest one and
+//     keys keep their lexicographic order, see escapeVersionedKey
+//   - `xvb` + BE(MaxUint64-block) lists the version keys written for a block that is
+//     not final yet, so that they can be removed if the block is undone
+//   - `xvs` holds the oldest block versioned reads are answered for
+var versionKeyPrefix = []byte("xvk")
+var versionBlockPrefix = []byte("xvb")
+var versionsStartKey = []byte("xvs")
+
+const (
+	versionSet    byte = 's'
+	versionDelete byte = 'd'
+)
+
+type versions struct {
+	retentionBlocks uint64
+
+	// recording is true while the operations of block blockNum are added, versions
+	// are not recorded for the operations applied by an undo
+	recording        bool
+	blockNum         uint64
+	finalBlockHeight uint64
+
+	pending   map[uint64]map[string]*pbkv.KVOperation
+	deletions [][]byte
+}
+
+func newVersions(retentionBlocks uint64) *versions {
+	return &versions{
+		retentionBlocks: retentionBlocks,
+		pending:         make(map[uint64]map[string]*pbkv.KVOperation),
+	}
+}
+
+func (v *versions) startRecording(blockNum, finalBlockHeight uint64) {
+	v.recording = true
+	v.blockNum = blockNum
+	v.finalBlockHeight = finalBlockHeight
+}
+
+func (v *versions) stopRecording() {
+	v.recording = false
+}
+
+// record keeps op, a SET or a DELETE, as the version of its key for the block being
+// handled, the last operation on a key within a block wins.
+func (v *versions) record(op *pbkv.KVOperation) {
+	if !v.recording {
+		return
+	}
+
+	ops, found := v.pending[v.blockNum]
+	if !found {
+		ops = make(map[string]*pbkv.KVOperation)
+		v.pending[v.blockNum] = ops
+	}
+	ops[op.Key] = op
+}
+
+// cutoff is the block below which versions can be purged, the newest version of a
+// key at or below it is still needed to answer reads at the cutoff block.
+func (v *versions) cutoff() uint64 {
+	if v.retentionBlocks == 0 || v.finalBlockHeight <= v.retentionBlocks {
+		return 0
+	}
+	return v.finalBlockHeight - v.retentionBlocks
+}
+
+func (v *versions) reset() {
+	v.pending = make(map[uint64]map[string]*pbkv.KVOperation)
+	v.deletions = nil
+}
+
+// recordPrefixDeletion records a DELETE version for every key that exists under prefix
+// once pending operations are applied.
+func (db *OperationDB) recordPrefixDeletion(ctx context.Context, prefix string) error {
+	if !db.versions.recording {
+		return nil
+	}
+
+	keys := map[string]bool{}
+	stored, err := db.prefixKeys(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("listing keys for prefix %q: %w", prefix, err)
+	}
+	for _, key := range stored {
+		keys[fromUserKey(key)] = true
+	}
+	for key := range db.pendingOperations {
+		if strings.HasPrefix(key, prefix) {
+			keys[key] = true
+		}
+	}
+
+	for key := range keys {
+		_, found, err := db.currentValue(ctx, key)
+		if err != nil {
+			return err
+		}
+		if found {
+			db.versions.record(&pbkv.KVOperation{Type: pbkv.KVOperation_DELETE, Key: key})
+		}
+	}
+	return nil
+}
+
+// flushVersions adds the pending versions to batch and purges the versions that fell
+// out of the retention window.
+func (db *OperationDB) flushVersions(ctx context.Context, batch *flushBatch) error {
+	start, found, err := db.versionsStart(ctx)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if start, err = db.snapshotVersions(ctx); err != nil {
+			return fmt.Errorf("snapshotting existing keys: %w", err)
+		}
+	}
+
+	for _, key := range db.versions.deletions {
+		batch.Delete(key)
+	}
+
+	cutoff := db.versions.cutoff()
+	newestBelowCutoff := map[string]uint64{}
+	for blockNum, ops := range db.versions.pending {
+		if blockNum > cutoff {
+			continue
+		}
+		for key := range ops {
+			if blockNum >= newestBelowCutoff[key] {
+				newestBelowCutoff[key] = blockNum
+			}
+		}
+	}
+
+	purged := map[string]bool{}
+	for blockNum, ops := range db.versions.pending {
+		var blockKeys [][]byte
+		for key, op := range ops {
+			if cutoff > 0 && !purged[key] {
+				purged[key] = true
+				_, pendingBelowCutoff := newestBelowCutoff[key]
+				if err := db.purgeVersions(ctx, batch, key, cutoff, !pendingBelowCutoff); err != nil {
+					return fmt.Errorf("purging versions of key %q: %w", key, err)
+				}
+			}
+
+			if blockNum <= cutoff && newestBelowCutoff[key] != blockNum {
+				continue
+			}
+
+			versionedKey := versionKey(key, blockNum)
+			batch.Put(versionedKey, encodeVersion(op))
+			blockKeys = append(blockKeys, versionedKey)
+		}
+
+		if blockNum > db.versions.finalBlockHeight && len(blockKeys) > 0 {
+			batch.Put(versionBlockKey(blockNum), encodeVersionKeys(blockKeys))
+		}
+	}
+
+	if cutoff > start {
+		start = cutoff
+	}
+	batch.Put(versionsStartKey, binary.BigEndian.AppendUint64(nil, start))
+	return nil
+}
+
+// snapshotVersions records the current value of every key as its version at the block
+// of the stored cursor, so that keys that do not change after versioning is enabled
+// can still be read at a block. The versions are committed in chunks of about
+// walChunkSize bytes ahead of the flush, the snapshot being started over if the flush
+// does not complete since the versions start is only written by the flush.
+func (db *OperationDB) snapshotVersions(ctx context.Context) (blockNum uint64, err error) {
+	cursor, err := db.GetCursor(ctx)
+	if err != nil {
+		if errors.Is(err, ErrCursorNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	blockNum = cursor.Block().Num()
+
+	start, end := []byte{userKeyPrefix}, prefixEnd([]byte{userKeyPrefix})
+	for {
+		batch, size := &flushBatch{}, 0
+		var last []byte
+
+		itr := db.store.Scan(ctx, start, end, 0)
+		for size < walChunkSize && itr.Next() {
+			it := itr.Item()
+			key, value := versionKey(fromUserKey(it.Key), blockNum), append([]byte{versionSet}, it.Value...)
+			batch.Put(key, value)
+			size += len(key) + len(value)
+			last = it.Key
+		}
+		if err := itr.Err(); err != nil {
+			return 0, err
+		}
+		if last == nil {
+			return blockNum, nil
+		}
+
+		if err := db.commit(ctx, batch); err != nil {
+			return 0, err
+		}
+		if size < walChunkSize {
+			return blockNum, nil
+		}
+		start = append(append([]byte{}, last...), 0x00)
+	}
+}
+
+// purgeVersions deletes the stored versions of key at or below cutoff, keeping the
+// newest of them if keepNewest is true.
+func (db *OperationDB) purgeVersions(ctx context.Context, batch *flushBatch, key string, cutoff uint64, keepNewest bool) error {
+	itr := db.store.Scan(ctx, versionKey(key, cutoff), versionKeyEnd(key), 0, store.KeyOnly())
+	for itr.Next() {
+		if keepNewest {
+			keepNewest = false
+			continue
+		}
+		batch.Delete(itr.Item().Key)
+	}
+	return itr.Err()
+}
+
+// PurgeVersionBlocks forgets the version keys written for blocks that are now final,
+// they can no longer be undone.
+func (db *OperationDB) PurgeVersionBlocks(ctx context.Context, finalBlockHeight uint64) error {
+	keys := make([][]byte, 0)
+
+	scanOutput := db.store.Scan(ctx, versionBlockKey(finalBlockHeight), versionBlockKey(0), 0, store.KeyOnly())
+	for scanOutput.Next() {
+		keys = append(keys, scanOutput.Item().Key)
+	}
+	if scanOutput.Err() != nil {
+		return fmt.Errorf("scanning version blocks for block %d: %w", finalBlockHeight, scanOutput.Err())
+	}
+
+	return db.store.BatchDelete(ctx, keys)
+}
+
+// undoVersions removes the versions written for the blocks above lastValidBlock.
+func (db *OperationDB) undoVersions(ctx context.Context, lastValidBlock uint64) error {
+	for blockNum := range db.versions.pending {
+		if blockNum > lastValidBlock {
+			delete(db.versions.pending, blockNum)
+		}
+	}
+
+	itr := db.store.Scan(ctx, versionBlockKey(math.MaxUint64), versionBlockKey(lastValidBlock), 0)
+	for itr.Next() {
+		keys, err := decodeVersionKeys(itr.Item().Value)
+		if err != nil {
+			return err
+		}
+		db.versions.deletions = append(db.versions.deletions, keys...)
+		db.versions.deletions = append(db.versions.deletions, itr.Item().Key)
+	}
+	return itr.Err()
+}
+
+func (db *OperationDB) versionsStart(ctx context.Context) (blockNum uint64, found bool, err error) {
+	value, err := db.store.Get(ctx, versionsStartKey)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if len(value) != 8 {
+		return 0, false, fmt.Errorf("invalid versions start value")
+	}
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+func (db *OperationDB) checkAtBlock(ctx context.Context, atBlock uint64) error {
+	start, found, err := db.versionsStart(ctx)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: request value for 'at_block' requires the sink to run with versioned keys", ErrInvalidArguments)
+	}
+	if atBlock < start {
+		return fmt.Errorf("%w: request value for 'at_block' must be at least %d, the oldest retained block, but received %d", ErrInvalidArguments, start, atBlock)
+	}
+	return nil
+}
+
+func (db *OperationDB) getAt(ctx context.Context, key string, atBlock uint64) ([]byte, error) {
+	if err := db.checkAtBlock(ctx, atBlock); err != nil {
+		return nil, err
+	}
+
+	version, err := db.versionAt(ctx, key, atBlock)
+	if err != nil {
+		return nil, err
+	}
+	if len(version) == 0 || version[0] != versionSet {
+		return nil, ErrNotFound
+	}
+	return version[1:], nil
+}
+
+// versionAt returns the stored version of key at atBlock, the newest one at or below
+// it, nil if key has none.
+func (db *OperationDB) versionAt(ctx context.Context, key string, atBlock uint64) ([]byte, error) {
+	it, found, err := firstItem(db.store.Scan(ctx, versionKey(key, atBlock), versionKeyEnd(key), 1))
+	if err != nil || !found {
+		return nil, err
+	}
+	return it.Value, nil
+}
+
+// firstItem returns the first item of itr, found being false when it has none.
+func firstItem(itr *store.Iterator) (item store.KV, found bool, err error) {
+	if itr.Next() {
+		return itr.Item(), true, nil
+	}
+	return store.KV{}, false, itr.Err()
+}
+
+// scanAt returns the keys in [start, exclusiveEnd) of the versioned keyspace with the
+// value they had at atBlock, from the end of the range backward for reverse reads.
+// Versions are told apart by their value, keys only reads still read them. The range
+// is walked one key at a time, seeking past the versions of each key, until limit+1
+// keys having a value at atBlock are found.
+func (db *OperationDB) scanAt(ctx context.Context, start, exclusiveEnd []byte, atBlock uint64, limit int, options *ReadOptions) (values []*pbkv.KV, limitReached bool, err error) {
+	if err := db.checkAtBlock(ctx, atBlock); err != nil {
+		return nil, false, err
+	}
+
+	if options.Reverse {
+		return db.reverseScanAt(ctx, start, exclusiveEnd, atBlock, limit, options)
+	}
+
+	for {
+		it, found, err := firstItem(db.store.Scan(ctx, start, exclusiveEnd, 1))
+		if err != nil {
+			return nil, false, err
+		}
+		if !found {
+			break
+		}
+		key, blockNum, err := decodeVersionKey(it.Key)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// versions are sorted from newest to oldest, the first one is the value at
+		// atBlock unless it was written above it
+		version := it.Value
+		if blockNum > atBlock {
+			if version, err = db.versionAt(ctx, key, atBlock); err != nil {
+				return nil, false, err
+			}
+		}
+		if len(version) > 0 && version[0] == versionSet {
+			if len(values) == limit {
+				limitReached = true
+				break
+			}
+			values = append(values, options.keyValue(key, version[1:]))
+		}
+		start = versionKeyEnd(key)
+	}
+
+	if len(values) == 0 {
+		return nil, false, ErrNotFound
+	}
+	return values, limitReached, nil
+}
+
+func encodeVersion(op *pbkv.KVOperation) []byte {
+	if op.Type == pbkv.KVOperation_DELETE {
+		return []byte{versionDelete}
+	}
+	return append([]byte{versionSet}, op.Value...)
+}
+
+func encodeVersionKeys(keys [][]byte) []byte {
+	sort.Slice(keys, func(i, j int) bool { return string(keys[i]) < string(keys[j]) })
+
+	var out []byte
+	for _, key := range keys {
+		out = binary.AppendUvarint(out, uint64(len(key)))
+		out = append(out, key...)
+	}
+	return out
+}
+
+func decodeVersionKeys(in []byte) (keys [][]byte, err error) {
+	for len(in) > 0 {
+		length, n := binary.Uvarint(in)
+		if n <= 0 || uint64(len(in)-n) < length {
+			return nil, fmt.Errorf("invalid version block entry")
+		}
+		keys = append(keys, in[n:n+int(length)])
+		in = in[n+int(length):]
+	}
+	return keys, nil
+}
+
+// escapeVersionedKey escapes the 0x00 bytes of key as 0x00 0xFF, the key being then
+// terminated by 0x00 0x01. The escaping preserves the lexicographic order of keys and
+// is prefix preserving, so a prefix or a range of user keys maps to a prefix or a
+// range of versioned keys.
+func escapeVersionedKey(key string) []byte {
+	out := make([]byte, 0, len(key)+2)
+	for i := 0; i < len(key); i++ {
+		out = append(out, key[i])
+		if key[i] == 0x00 {
+			out = append(out, 0xFF)
+		}
+	}
+	return out
+}
+
+func versionKeyStart(key string) []byte {
+	return append(append([]byte{}, versionKeyPrefix...), escapeVersionedKey(key)...)
+}
+
+func versionKey(key string, blockNum uint64) []byte {
+	out := append(versionKeyStart(key), 0x00, 0x01)
+	return binary.BigEndian.AppendUint64(out, math.MaxUint64-blockNum)
+}
+
+// versionKeyEnd is the exclusive end of the versions of key.
+func versionKeyEnd(key string) []byte {
+	return append(versionKeyStart(key), 0x00, 0x02)
+}
+
+func decodeVersionKey(in []byte) (key string, blockNum uint64, err error) {
+	if len(in) < len(versionKeyPrefix)+2+8 {
+		return "", 0, fmt.Errorf("invalid version key")
+	}
+
+	escaped := in[len(versionKeyPrefix) : len(in)-8]
+	out := make([]byte, 0, len(escaped))
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] != 0x00 {
+			out = append(out, escaped[i])
+			continue
+		}
+		if i+1 >= len(escaped) {
+			return "", 0, fmt.Errorf("invalid version key")
+		}
+		switch escaped[i+1] {
+		case 0xFF:
+			out = append(out, 0x00)
+			i++
+		case 0x01:
+			if i+2 != len(escaped) {
+				return "", 0, fmt.Errorf("invalid version key")
+			}
+			return string(out), math.MaxUint64 - binary.BigEndian.Uint64(in[len(in)-8:]), nil
+		default:
+			return "", 0, fmt.Errorf("invalid version key")
+		}
+	}
+	return "", 0, fmt.Errorf("invalid version key")
+}
+
+func versionBlockKey(blockNum uint64) []byte {
+	out := append([]byte{}, versionBlockPrefix...)
+	return binary.BigEndian.AppendUint64(out, math.MaxUint64-blockNum)
+}
+
+// prefixEnd returns the exclusive end of the keys starting with prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xFF {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return InfiniteEndBytes
+}
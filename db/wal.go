@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/streamingfast/kvdb/store"
+	"go.uber.org/zap"
+)
+
+// The write-ahead marker is what makes a flush crash consistent, kvdb stores having no
+// way to commit a set of writes atomically. The whole flush (user keys, undo entries
+// and cursor) is first persisted under the `xw` keyspace, then applied, then the
+// marker is removed. The commit key is written last, so a marker without it was never
+// complete and is simply discarded, while a complete one is replayed on startup.
+var walCommitKey = []byte("xwc")
+var walChunkPrefix = []byte("xwd")
+
+// walChunkSize keeps each marker value well below the per-value limits of backends
+// like TiKV or Bigtable.
+const walChunkSize = 4 * 1024 * 1024
+
+const (
+	walOpPut    byte = 'p'
+	walOpDelete byte = 'd'
+)
+
+type flushBatch struct {
+	puts    []store.KV
+	deletes [][]byte
+}
+
+func (b *flushBatch) Put(key, value []byte) {
+	b.puts = append(b.puts, store.KV{Key: key, Value: value})
+}
+
+func (b *flushBatch) Delete(key []byte) {
+	b.deletes = append(b.deletes, key)
+}
+
+func (b *flushBatch) encode() []byte {
+	size := 0
+	for _, kv := range b.puts {
+		size += 1 + 2*binary.MaxVarintLen64 + len(kv.Key) + len(kv.Value)
+	}
+	for _, key := range b.deletes {
+		size += 1 + binary.MaxVarintLen64 + len(key)
+	}
+
+	out := make([]byte, 0, size)
+	for _, kv := range b.puts {
+		out = append(out, walOpPut)
+		out = binary.AppendUvarint(out, uint64(len(kv.Key)))
+		out = append(out, kv.Key...)
+		out = binary.AppendUvarint(out, uint64(len(kv.Value)))
+		out = append(out, kv.Value...)
+	}
+	for _, key := range b.deletes {
+		out = append(out, walOpDelete)
+		out = binary.AppendUvarint(out, uint64(len(key)))
+		out = append(out, key...)
+	}
+	return out
+}
+
+func decodeFlushBatch(in []byte) (*flushBatch, error) {
+	b := &flushBatch{}
+	readBytes := func() ([]byte, error) {
+		length, n := binary.Uvarint(in)
+		if n <= 0 || uint64(len(in)-n) < length {
+			return nil, fmt.Errorf("invalid write-ahead marker entry")
+		}
+		out := in[n : n+int(length)]
+		in = in[n+int(length):]
+		return out, nil
+	}
+
+	for len(in) > 0 {
+		op := in[0]
+		in = in[1:]
+
+		key, err := readBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case walOpPut:
+			value, err := readBytes()
+			if err != nil {
+				return nil, err
+			}
+			b.Put(key, value)
+		case walOpDelete:
+			b.Delete(key)
+		default:
+			return nil, fmt.Errorf("invalid write-ahead marker operation %q", op)
+		}
+	}
+	return b, nil
+}
+
+// commit durably applies the batch through the write-ahead marker.
+func (db *OperationDB) commit(ctx context.Context, batch *flushBatch) error {
+	chunkCount, err := db.writeMarker(ctx, batch.encode())
+	if err != nil {
+		return fmt.Errorf("writing write-ahead marker: %w", err)
+	}
+
+	if err := db.apply(ctx, batch); err != nil {
+		return fmt.Errorf("applying flush: %w", err)
+	}
+
+	if err := db.deleteMarker(ctx, chunkCount); err != nil {
+		return fmt.Errorf("deleting write-ahead marker: %w", err)
+	}
+	return nil
+}
+
+func (db *OperationDB) apply(ctx context.Context, batch *flushBatch) error {
+	if len(batch.deletes) > 0 {
+		if err := db.store.BatchDelete(ctx, batch.deletes); err != nil {
+			return err
+		}
+	}
+
+	for _, kv := range batch.puts {
+		if err := db.store.Put(ctx, kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+	return db.store.FlushPuts(ctx)
+}
+
+func (db *OperationDB) writeMarker(ctx context.Context, data []byte) (chunkCount uint32, err error) {
+	for offset := 0; offset < len(data); offset += walChunkSize {
+		end := offset + walChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := db.store.Put(ctx, walChunkKey(chunkCount), data[offset:end]); err != nil {
+			return 0, err
+		}
+		chunkCount++
+	}
+	if err := db.store.FlushPuts(ctx); err != nil {
+		return 0, err
+	}
+
+	// The commit key is flushed on its own so that it can only exist once every chunk is durable
+	commit := make([]byte, 4)
+	binary.BigEndian.PutUint32(commit, chunkCount)
+	if err := db.store.Put(ctx, walCommitKey, commit); err != nil {
+		return 0, err
+	}
+	if err := db.store.FlushPuts(ctx); err != nil {
+		return 0, err
+	}
+	return chunkCount, nil
+}
+
+func (db *OperationDB) deleteMarker(ctx context.Context, chunkCount uint32) error {
+	// The commit key goes first, a crash in between leaves only dangling chunks which are discarded on recovery
+	if err := db.store.BatchDelete(ctx, [][]byte{walCommitKey}); err != nil {
+		return err
+	}
+
+	keys := make([][]byte, chunkCount)
+	for i := range keys {
+		keys[i] = walChunkKey(uint32(i))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return db.store.BatchDelete(ctx, keys)
+}
+
+// RecoverPendingFlush reconciles a flush that was interrupted before completion. A
+// committed write-ahead marker is replayed entirely, bringing user keys, undo entries
+// and cursor in sync, while an incomplete one is discarded since nothing was applied
+// yet. It must be called before reading the cursor on startup.
+func (db *OperationDB) RecoverPendingFlush(ctx context.Context) error {
+	commit, err := db.store.Get(ctx, walCommitKey)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("reading write-ahead marker: %w", err)
+	}
+
+	if errors.Is(err, store.ErrNotFound) {
+		return db.discardIncompleteMarker(ctx)
+	}
+
+	if len(commit) != 4 {
+		return fmt.Errorf("invalid write-ahead marker commit value")
+	}
+	chunkCount := binary.BigEndian.Uint32(commit)
+
+	var data []byte
+	for i := uint32(0); i < chunkCount; i++ {
+		chunk, err := db.store.Get(ctx, walChunkKey(i))
+		if err != nil {
+			return fmt.Errorf("reading write-ahead marker chunk %d: %w", i, err)
+		}
+		data = append(data, chunk...)
+	}
+
+	batch, err := decodeFlushBatch(data)
+	if err != nil {
+		return err
+	}
+
+	db.logger.Info("replaying interrupted flush from write-ahead marker", zap.Int("put_count", len(batch.puts)), zap.Int("delete_count", len(batch.deletes)))
+	if err := db.apply(ctx, batch); err != nil {
+		return fmt.Errorf("replaying write-ahead marker: %w", err)
+	}
+
+	return db.deleteMarker(ctx, chunkCount)
+}
+
+func (db *OperationDB) discardIncompleteMarker(ctx context.Context) error {
+	itr := db.store.Prefix(ctx, walChunkPrefix, 0, store.KeyOnly())
+
+	var keys [][]byte
+	for itr.Next() {
+		keys = append(keys, itr.Item().Key)
+	}
+	if err := itr.Err(); err != nil {
+		return fmt.Errorf("scanning write-ahead marker chunks: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	db.logger.Info("discarding incomplete write-ahead marker", zap.Int("chunk_count", len(keys)))
+	return db.store.BatchDelete(ctx, keys)
+}
+
+func walChunkKey(index uint32) []byte {
+	out := make([]byte, len(walChunkPrefix)+4)
+	copy(out, walChunkPrefix)
+	binary.BigEndian.PutUint32(out[len(walChunkPrefix):], index)
+	return out
+}
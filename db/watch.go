@@ -0,0 +1,98 @@
+package db
+
+import (
+	"errors"
+	"sync"
+
+	sink "github.com/streamingfast/substreams-sink"
+	pbkv "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+)
+
+var ErrSubscriptionLagging = errors.New("subscription is lagging behind, flushes were committed faster than they were consumed")
+
+// FlushEvent describes the user keys changed by a committed flush, Changes are SET
+// and DELETE operations, prefix deletions being expanded to the keys they deleted.
+type FlushEvent struct {
+	Changes []*pbkv.KVOperation
+	Cursor  *sink.Cursor
+
+	// Undo is true if the flush reverted blocks after a reorg, Cursor being then
+	// the last valid block
+	Undo bool
+}
+
+// Subscription receives an event for each flush committed after its creation. A
+// subscription that does not keep up is closed, Err returning ErrSubscriptionLagging,
+// so flushes are never slowed down by subscribers.
+type Subscription struct {
+	db     *OperationDB
+	events chan *FlushEvent
+	err    error
+}
+
+func (s *Subscription) Events() <-chan *FlushEvent {
+	return s.events
+}
+
+// Err returns why the events channel was closed, nil if it was through Close.
+func (s *Subscription) Err() error {
+	s.db.subscriptionsLock.Lock()
+	defer s.db.subscriptionsLock.Unlock()
+
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.db.subscriptionsLock.Lock()
+	defer s.db.subscriptionsLock.Unlock()
+
+	if _, found := s.db.subscriptions[s]; found {
+		delete(s.db.subscriptions, s)
+		close(s.events)
+	}
+}
+
+// Subscribe returns a subscription buffering up to bufferSize flush events.
+func (db *OperationDB) Subscribe(bufferSize int) *Subscription {
+	db.subscriptionsLock.Lock()
+	defer db.subscriptionsLock.Unlock()
+
+	s := &Subscription{db: db, events: make(chan *FlushEvent, bufferSize)}
+	if db.subscriptions == nil {
+		db.subscriptions = make(map[*Subscription]struct{})
+	}
+	db.subscriptions[s] = struct{}{}
+	return s
+}
+
+func (db *OperationDB) hasSubscriptions() bool {
+	db.subscriptionsLock.Lock()
+	defer db.subscriptionsLock.Unlock()
+
+	return len(db.subscriptions) > 0
+}
+
+func (db *OperationDB) notifyFlush(event *FlushEvent) {
+	db.subscriptionsLock.Lock()
+	defer db.subscriptionsLock.Unlock()
+
+	for s := range db.subscriptions {
+		select {
+		case s.events <- event:
+		default:
+			s.err = ErrSubscriptionLagging
+			delete(db.subscriptions, s)
+			close(s.events)
+		}
+	}
+}
+
+// subscriptionsState is embedded in OperationDB, subscriptions are read and written
+// by the server while flushes happen on the sinker side.
+type subscriptionsState struct {
+	subscriptionsLock sync.Mutex
+	subscriptions     map[*Subscription]struct{}
+
+	// committed is closed on the next commit of a cursor, see WaitForBlock
+	committed chan struct{}
+}
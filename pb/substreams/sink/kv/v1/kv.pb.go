@@ -23,9 +23,13 @@ const (
 type KVOperation_Type int32
 
 const (
-	KVOperation_UNSET  KVOperation_Type = 0 // Protobuf default should not be used, this is used so that the consume can ensure that the value was actually specified
-	KVOperation_SET    KVOperation_Type = 1
-	KVOperation_DELETE KVOperation_Type = 2
+	KVOperation_UNSET         KVOperation_Type = 0 // Protobuf default should not be used, this is used so that the consume can ensure that the value was actually specified
+	KVOperation_SET           KVOperation_Type = 1
+	KVOperation_DELETE        KVOperation_Type = 2
+	KVOperation_DELETE_PREFIX KVOperation_Type = 3 // Deletes every key starting with the operation's key, which must not be empty, the value is ignored
+	KVOperation_ADD           KVOperation_Type = 4 // Adds the delta in value to the current numeric value of the key, see numeric_encoding
+	KVOperation_INCREMENT     KVOperation_Type = 5 // Adds one to the current numeric value of the key, the value is ignored, see numeric_encoding
+	KVOperation_APPEND        KVOperation_Type = 6 // Appends the value to the current value of the key, see append_encoding
 )
 
 // Enum value maps for KVOperation_Type.
@@ -34,11 +38,19 @@ var (
 		0: "UNSET",
 		1: "SET",
 		2: "DELETE",
+		3: "DELETE_PREFIX",
+		4: "ADD",
+		5: "INCREMENT",
+		6: "APPEND",
 	}
 	KVOperation_Type_value = map[string]int32{
-		"UNSET":  0,
-		"SET":    1,
-		"DELETE": 2,
+		"UNSET":         0,
+		"SET":           1,
+		"DELETE":        2,
+		"DELETE_PREFIX": 3,
+		"ADD":           4,
+		"INCREMENT":     5,
+		"APPEND":        6,
 	}
 )
 
@@ -69,6 +81,110 @@ func (KVOperation_Type) EnumDescriptor() ([]byte, []int) {
 	return file_substreams_sink_kv_v1_kv_proto_rawDescGZIP(), []int{1, 0}
 }
 
+// NumericEncoding declares how the stored value of an ADD or INCREMENT operation is encoded. A missing key counts as zero.
+//
+// The delta of an ADD operation is an 8 bytes big-endian signed integer for INT64_BIG_ENDIAN and UINT64_BIG_ENDIAN
+// and a base 10 string for DECIMAL_STRING. Overflowing the encoding fails the sink.
+type KVOperation_NumericEncoding int32
+
+const (
+	KVOperation_NUMERIC_ENCODING_UNSET KVOperation_NumericEncoding = 0
+	KVOperation_INT64_BIG_ENDIAN       KVOperation_NumericEncoding = 1 // 8 bytes big-endian signed integer
+	KVOperation_UINT64_BIG_ENDIAN      KVOperation_NumericEncoding = 2 // 8 bytes big-endian unsigned integer
+	KVOperation_DECIMAL_STRING         KVOperation_NumericEncoding = 3 // Arbitrary precision signed integer written in base 10, like "-1234"
+)
+
+// Enum value maps for KVOperation_NumericEncoding.
+var (
+	KVOperation_NumericEncoding_name = map[int32]string{
+		0: "NUMERIC_ENCODING_UNSET",
+		1: "INT64_BIG_ENDIAN",
+		2: "UINT64_BIG_ENDIAN",
+		3: "DECIMAL_STRING",
+	}
+	KVOperation_NumericEncoding_value = map[string]int32{
+		"NUMERIC_ENCODING_UNSET": 0,
+		"INT64_BIG_ENDIAN":       1,
+		"UINT64_BIG_ENDIAN":      2,
+		"DECIMAL_STRING":         3,
+	}
+)
+
+func (x KVOperation_NumericEncoding) Enum() *KVOperation_NumericEncoding {
+	p := new(KVOperation_NumericEncoding)
+	*p = x
+	return p
+}
+
+func (x KVOperation_NumericEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KVOperation_NumericEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_substreams_sink_kv_v1_kv_proto_enumTypes[1].Descriptor()
+}
+
+func (KVOperation_NumericEncoding) Type() protoreflect.EnumType {
+	return &file_substreams_sink_kv_v1_kv_proto_enumTypes[1]
+}
+
+func (x KVOperation_NumericEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KVOperation_NumericEncoding.Descriptor instead.
+func (KVOperation_NumericEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_substreams_sink_kv_v1_kv_proto_rawDescGZIP(), []int{1, 1}
+}
+
+// AppendEncoding declares how the value of an APPEND operation is added to the current value of the key. A missing key
+// counts as empty.
+type KVOperation_AppendEncoding int32
+
+const (
+	KVOperation_RAW             KVOperation_AppendEncoding = 0 // Value bytes are appended as is
+	KVOperation_LENGTH_PREFIXED KVOperation_AppendEncoding = 1 // Value bytes are appended after their length written as a 4 bytes big-endian unsigned integer
+)
+
+// Enum value maps for KVOperation_AppendEncoding.
+var (
+	KVOperation_AppendEncoding_name = map[int32]string{
+		0: "RAW",
+		1: "LENGTH_PREFIXED",
+	}
+	KVOperation_AppendEncoding_value = map[string]int32{
+		"RAW":             0,
+		"LENGTH_PREFIXED": 1,
+	}
+)
+
+func (x KVOperation_AppendEncoding) Enum() *KVOperation_AppendEncoding {
+	p := new(KVOperation_AppendEncoding)
+	*p = x
+	return p
+}
+
+func (x KVOperation_AppendEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KVOperation_AppendEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_substreams_sink_kv_v1_kv_proto_enumTypes[2].Descriptor()
+}
+
+func (KVOperation_AppendEncoding) Type() protoreflect.EnumType {
+	return &file_substreams_sink_kv_v1_kv_proto_enumTypes[2]
+}
+
+func (x KVOperation_AppendEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KVOperation_AppendEncoding.Descriptor instead.
+func (KVOperation_AppendEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_substreams_sink_kv_v1_kv_proto_rawDescGZIP(), []int{1, 2}
+}
+
 type KVOperations struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -121,10 +237,23 @@ type KVOperation struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Key     string           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value   []byte           `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	Ordinal uint64           `protobuf:"varint,3,opt,name=ordinal,proto3" json:"ordinal,omitempty"`
-	Type    KVOperation_Type `protobuf:"varint,4,opt,name=type,proto3,enum=sf.substreams.sink.kv.v1.KVOperation_Type" json:"type,omitempty"`
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Operations of a block are applied by ascending ordinal, operations sharing the same ordinal are applied in the
+	// order they were emitted. The last operation applied to a key wins.
+	Ordinal         uint64                      `protobuf:"varint,3,opt,name=ordinal,proto3" json:"ordinal,omitempty"`
+	Type            KVOperation_Type            `protobuf:"varint,4,opt,name=type,proto3,enum=sf.substreams.sink.kv.v1.KVOperation_Type" json:"type,omitempty"`
+	NumericEncoding KVOperation_NumericEncoding `protobuf:"varint,5,opt,name=numeric_encoding,json=numericEncoding,proto3,enum=sf.substreams.sink.kv.v1.KVOperation_NumericEncoding" json:"numeric_encoding,omitempty"`
+	AppendEncoding  KVOperation_AppendEncoding  `protobuf:"varint,6,opt,name=append_encoding,json=appendEncoding,proto3,enum=sf.substreams.sink.kv.v1.KVOperation_AppendEncoding" json:"append_encoding,omitempty"`
+	// Expiry of the key written by the operation, the sink deletes the key once a block at or above expires_at_block, or
+	// with a timestamp at or above expires_at_timestamp (unix seconds), is reached. Expired keys are deleted before the
+	// operations of that block, like any DELETE they are restored if the block is undone. Each write to a key replaces
+	// its expiry, a write without one makes the key permanent again.
+	//
+	// Types that are assignable to Expiry:
+	//	*KVOperation_ExpiresAtBlock
+	//	*KVOperation_ExpiresAtTimestamp
+	Expiry isKVOperation_Expiry `protobuf_oneof:"expiry"`
 }
 
 func (x *KVOperation) Reset() {
@@ -187,6 +316,57 @@ func (x *KVOperation) GetType() KVOperation_Type {
 	return KVOperation_UNSET
 }
 
+func (x *KVOperation) GetNumericEncoding() KVOperation_NumericEncoding {
+	if x != nil {
+		return x.NumericEncoding
+	}
+	return KVOperation_NUMERIC_ENCODING_UNSET
+}
+
+func (x *KVOperation) GetAppendEncoding() KVOperation_AppendEncoding {
+	if x != nil {
+		return x.AppendEncoding
+	}
+	return KVOperation_RAW
+}
+
+func (m *KVOperation) GetExpiry() isKVOperation_Expiry {
+	if m != nil {
+		return m.Expiry
+	}
+	return nil
+}
+
+func (x *KVOperation) GetExpiresAtBlock() uint64 {
+	if x, ok := x.GetExpiry().(*KVOperation_ExpiresAtBlock); ok {
+		return x.ExpiresAtBlock
+	}
+	return 0
+}
+
+func (x *KVOperation) GetExpiresAtTimestamp() uint64 {
+	if x, ok := x.GetExpiry().(*KVOperation_ExpiresAtTimestamp); ok {
+		return x.ExpiresAtTimestamp
+	}
+	return 0
+}
+
+type isKVOperation_Expiry interface {
+	isKVOperation_Expiry()
+}
+
+type KVOperation_ExpiresAtBlock struct {
+	ExpiresAtBlock uint64 `protobuf:"varint,7,opt,name=expires_at_block,json=expiresAtBlock,proto3,oneof"`
+}
+
+type KVOperation_ExpiresAtTimestamp struct {
+	ExpiresAtTimestamp uint64 `protobuf:"varint,8,opt,name=expires_at_timestamp,json=expiresAtTimestamp,proto3,oneof"`
+}
+
+func (*KVOperation_ExpiresAtBlock) isKVOperation_Expiry() {}
+
+func (*KVOperation_ExpiresAtTimestamp) isKVOperation_Expiry() {}
+
 var File_substreams_sink_kv_v1_kv_proto protoreflect.FileDescriptor
 
 var file_substreams_sink_kv_v1_kv_proto_rawDesc = []byte{
@@ -199,7 +379,7 @@ var file_substreams_sink_kv_v1_kv_proto_rawDesc = []byte{
 	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x73,
 	0x69, 0x6e, 0x6b, 0x2e, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x4f, 0x70, 0x65, 0x72,
 	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x22, 0xb7, 0x01, 0x0a, 0x0b, 0x4b, 0x56, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x73, 0x22, 0xb9, 0x05, 0x0a, 0x0b, 0x4b, 0x56, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
 	0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
 	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
 	0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x72, 0x64,
@@ -208,25 +388,57 @@ var file_substreams_sink_kv_v1_kv_proto_rawDesc = []byte{
 	0x0e, 0x32, 0x2a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
 	0x73, 0x2e, 0x73, 0x69, 0x6e, 0x6b, 0x2e, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x4f,
 	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74,
-	0x79, 0x70, 0x65, 0x22, 0x26, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x55,
-	0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x53, 0x45, 0x54, 0x10, 0x01, 0x12,
-	0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x42, 0xf7, 0x01, 0x0a, 0x1c,
-	0x63, 0x6f, 0x6d, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
-	0x73, 0x2e, 0x73, 0x69, 0x6e, 0x6b, 0x2e, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x42, 0x07, 0x4b, 0x76,
-	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x49, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73,
-	0x74, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2d, 0x73, 0x69, 0x6e,
-	0x6b, 0x2d, 0x6b, 0x76, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x73, 0x2f, 0x73, 0x69, 0x6e, 0x6b, 0x2f, 0x6b, 0x76, 0x2f, 0x76, 0x31, 0x3b, 0x6b, 0x76,
-	0x76, 0x31, 0xa2, 0x02, 0x04, 0x53, 0x53, 0x53, 0x4b, 0xaa, 0x02, 0x18, 0x53, 0x66, 0x2e, 0x53,
-	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x53, 0x69, 0x6e, 0x6b, 0x2e, 0x4b,
-	0x76, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x18, 0x53, 0x66, 0x5c, 0x53, 0x75, 0x62, 0x73, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x73, 0x5c, 0x53, 0x69, 0x6e, 0x6b, 0x5c, 0x4b, 0x76, 0x5c, 0x56, 0x31, 0xe2,
-	0x02, 0x24, 0x53, 0x66, 0x5c, 0x53, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x5c,
-	0x53, 0x69, 0x6e, 0x6b, 0x5c, 0x4b, 0x76, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x1c, 0x53, 0x66, 0x3a, 0x3a, 0x53, 0x75, 0x62,
-	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x3a, 0x3a, 0x53, 0x69, 0x6e, 0x6b, 0x3a, 0x3a, 0x4b,
-	0x76, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x79, 0x70, 0x65, 0x12, 0x60, 0x0a, 0x10, 0x6e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x5f, 0x65,
+	0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x35, 0x2e,
+	0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x73, 0x69,
+	0x6e, 0x6b, 0x2e, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x52, 0x0f, 0x6e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x45, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x5d, 0x0a, 0x0f, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x5f,
+	0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x34,
+	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x73,
+	0x69, 0x6e, 0x6b, 0x2e, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x52, 0x0e, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x12, 0x2a, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f,
+	0x61, 0x74, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00,
+	0x52, 0x0e, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x12, 0x32, 0x0a, 0x14, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00,
+	0x52, 0x12, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x22, 0x5d, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x09, 0x0a, 0x05,
+	0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x53, 0x45, 0x54, 0x10, 0x01,
+	0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d,
+	0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x50, 0x52, 0x45, 0x46, 0x49, 0x58, 0x10, 0x03, 0x12,
+	0x07, 0x0a, 0x03, 0x41, 0x44, 0x44, 0x10, 0x04, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x43, 0x52,
+	0x45, 0x4d, 0x45, 0x4e, 0x54, 0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x50, 0x50, 0x45, 0x4e,
+	0x44, 0x10, 0x06, 0x22, 0x6e, 0x0a, 0x0f, 0x4e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x45, 0x6e,
+	0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1a, 0x0a, 0x16, 0x4e, 0x55, 0x4d, 0x45, 0x52, 0x49,
+	0x43, 0x5f, 0x45, 0x4e, 0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54,
+	0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x49, 0x4e, 0x54, 0x36, 0x34, 0x5f, 0x42, 0x49, 0x47, 0x5f,
+	0x45, 0x4e, 0x44, 0x49, 0x41, 0x4e, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x55, 0x49, 0x4e, 0x54,
+	0x36, 0x34, 0x5f, 0x42, 0x49, 0x47, 0x5f, 0x45, 0x4e, 0x44, 0x49, 0x41, 0x4e, 0x10, 0x02, 0x12,
+	0x12, 0x0a, 0x0e, 0x44, 0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e,
+	0x47, 0x10, 0x03, 0x22, 0x2e, 0x0a, 0x0e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x07, 0x0a, 0x03, 0x52, 0x41, 0x57, 0x10, 0x00, 0x12, 0x13,
+	0x0a, 0x0f, 0x4c, 0x45, 0x4e, 0x47, 0x54, 0x48, 0x5f, 0x50, 0x52, 0x45, 0x46, 0x49, 0x58, 0x45,
+	0x44, 0x10, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x42, 0xf7, 0x01,
+	0x0a, 0x1c, 0x63, 0x6f, 0x6d, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x73, 0x2e, 0x73, 0x69, 0x6e, 0x6b, 0x2e, 0x6b, 0x76, 0x2e, 0x76, 0x31, 0x42, 0x07,
+	0x4b, 0x76, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x49, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66,
+	0x61, 0x73, 0x74, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2d, 0x73,
+	0x69, 0x6e, 0x6b, 0x2d, 0x6b, 0x76, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x73, 0x2f, 0x73, 0x69, 0x6e, 0x6b, 0x2f, 0x6b, 0x76, 0x2f, 0x76, 0x31, 0x3b,
+	0x6b, 0x76, 0x76, 0x31, 0xa2, 0x02, 0x04, 0x53, 0x53, 0x53, 0x4b, 0xaa, 0x02, 0x18, 0x53, 0x66,
+	0x2e, 0x53, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x53, 0x69, 0x6e, 0x6b,
+	0x2e, 0x4b, 0x76, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x18, 0x53, 0x66, 0x5c, 0x53, 0x75, 0x62, 0x73,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x5c, 0x53, 0x69, 0x6e, 0x6b, 0x5c, 0x4b, 0x76, 0x5c, 0x56,
+	0x31, 0xe2, 0x02, 0x24, 0x53, 0x66, 0x5c, 0x53, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x73, 0x5c, 0x53, 0x69, 0x6e, 0x6b, 0x5c, 0x4b, 0x76, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x1c, 0x53, 0x66, 0x3a, 0x3a, 0x53,
+	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x3a, 0x3a, 0x53, 0x69, 0x6e, 0x6b, 0x3a,
+	0x3a, 0x4b, 0x76, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -241,21 +453,25 @@ func file_substreams_sink_kv_v1_kv_proto_rawDescGZIP() []byte {
 	return file_substreams_sink_kv_v1_kv_proto_rawDescData
 }
 
-var file_substreams_sink_kv_v1_kv_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_substreams_sink_kv_v1_kv_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
 var file_substreams_sink_kv_v1_kv_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_substreams_sink_kv_v1_kv_proto_goTypes = []interface{}{
-	(KVOperation_Type)(0), // 0: sf.substreams.sink.kv.v1.KVOperation.Type
-	(*KVOperations)(nil),  // 1: sf.substreams.sink.kv.v1.KVOperations
-	(*KVOperation)(nil),   // 2: sf.substreams.sink.kv.v1.KVOperation
+	(KVOperation_Type)(0),            // 0: sf.substreams.sink.kv.v1.KVOperation.Type
+	(KVOperation_NumericEncoding)(0), // 1: sf.substreams.sink.kv.v1.KVOperation.NumericEncoding
+	(KVOperation_AppendEncoding)(0),  // 2: sf.substreams.sink.kv.v1.KVOperation.AppendEncoding
+	(*KVOperations)(nil),             // 3: sf.substreams.sink.kv.v1.KVOperations
+	(*KVOperation)(nil),              // 4: sf.substreams.sink.kv.v1.KVOperation
 }
 var file_substreams_sink_kv_v1_kv_proto_depIdxs = []int32{
-	2, // 0: sf.substreams.sink.kv.v1.KVOperations.operations:type_name -> sf.substreams.sink.kv.v1.KVOperation
+	4, // 0: sf.substreams.sink.kv.v1.KVOperations.operations:type_name -> sf.substreams.sink.kv.v1.KVOperation
 	0, // 1: sf.substreams.sink.kv.v1.KVOperation.type:type_name -> sf.substreams.sink.kv.v1.KVOperation.Type
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	1, // 2: sf.substreams.sink.kv.v1.KVOperation.numeric_encoding:type_name -> sf.substreams.sink.kv.v1.KVOperation.NumericEncoding
+	2, // 3: sf.substreams.sink.kv.v1.KVOperation.append_encoding:type_name -> sf.substreams.sink.kv.v1.KVOperation.AppendEncoding
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_substreams_sink_kv_v1_kv_proto_init() }
@@ -289,12 +505,16 @@ func file_substreams_sink_kv_v1_kv_proto_init() {
 			}
 		}
 	}
+	file_substreams_sink_kv_v1_kv_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*KVOperation_ExpiresAtBlock)(nil),
+		(*KVOperation_ExpiresAtTimestamp)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_substreams_sink_kv_v1_kv_proto_rawDesc,
-			NumEnums:      1,
+			NumEnums:      3,
 			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
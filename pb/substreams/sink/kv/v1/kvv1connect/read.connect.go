@@ -41,27 +41,52 @@ const (
 	KvGetByPrefixProcedure = "/sf.substreams.sink.kv.v1.Kv/GetByPrefix"
 	// KvScanProcedure is the fully-qualified name of the Kv's Scan RPC.
 	KvScanProcedure = "/sf.substreams.sink.kv.v1.Kv/Scan"
+	// KvWatchProcedure is the fully-qualified name of the Kv's Watch RPC.
+	KvWatchProcedure = "/sf.substreams.sink.kv.v1.Kv/Watch"
+	// KvReadChangesProcedure is the fully-qualified name of the Kv's ReadChanges RPC.
+	KvReadChangesProcedure = "/sf.substreams.sink.kv.v1.Kv/ReadChanges"
+	// KvQueryIndexProcedure is the fully-qualified name of the Kv's QueryIndex RPC.
+	KvQueryIndexProcedure = "/sf.substreams.sink.kv.v1.Kv/QueryIndex"
+	// KvDescribeValueTypesProcedure is the fully-qualified name of the Kv's DescribeValueTypes RPC.
+	KvDescribeValueTypesProcedure = "/sf.substreams.sink.kv.v1.Kv/DescribeValueTypes"
+	// KvCountProcedure is the fully-qualified name of the Kv's Count RPC.
+	KvCountProcedure = "/sf.substreams.sink.kv.v1.Kv/Count"
 )
 
 // These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
 var (
-	kvServiceDescriptor           = v1.File_substreams_sink_kv_v1_read_proto.Services().ByName("Kv")
-	kvGetMethodDescriptor         = kvServiceDescriptor.Methods().ByName("Get")
-	kvGetManyMethodDescriptor     = kvServiceDescriptor.Methods().ByName("GetMany")
-	kvGetByPrefixMethodDescriptor = kvServiceDescriptor.Methods().ByName("GetByPrefix")
-	kvScanMethodDescriptor        = kvServiceDescriptor.Methods().ByName("Scan")
+	kvServiceDescriptor                  = v1.File_substreams_sink_kv_v1_read_proto.Services().ByName("Kv")
+	kvGetMethodDescriptor                = kvServiceDescriptor.Methods().ByName("Get")
+	kvGetManyMethodDescriptor            = kvServiceDescriptor.Methods().ByName("GetMany")
+	kvGetByPrefixMethodDescriptor        = kvServiceDescriptor.Methods().ByName("GetByPrefix")
+	kvScanMethodDescriptor               = kvServiceDescriptor.Methods().ByName("Scan")
+	kvWatchMethodDescriptor              = kvServiceDescriptor.Methods().ByName("Watch")
+	kvReadChangesMethodDescriptor        = kvServiceDescriptor.Methods().ByName("ReadChanges")
+	kvQueryIndexMethodDescriptor         = kvServiceDescriptor.Methods().ByName("QueryIndex")
+	kvDescribeValueTypesMethodDescriptor = kvServiceDescriptor.Methods().ByName("DescribeValueTypes")
+	kvCountMethodDescriptor              = kvServiceDescriptor.Methods().ByName("Count")
 )
 
 // KvClient is a client for the sf.substreams.sink.kv.v1.Kv service.
 type KvClient interface {
 	// Get returns the requested value as bytes if it exists, not found error code otherwise.
 	Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error)
-	// GetMany returns the requested values as bytes if all of them exists, not found error code otherwise.
+	// GetMany returns one entry per requested key, in request order, flagging whether each key was found.
 	GetMany(context.Context, *connect.Request[v1.GetManyRequest]) (*connect.Response[v1.GetManyResponse], error)
 	// GetByPrefix returns the next _limit_ key/value pair that match the requested prefix if any exist, not found error code otherwise.
 	GetByPrefix(context.Context, *connect.Request[v1.GetByPrefixRequest]) (*connect.Response[v1.GetByPrefixResponse], error)
 	// Scan returns then next _limit_ key/value pairs starting lexicographically at the given key, not found error code otherwise.
 	Scan(context.Context, *connect.Request[v1.ScanRequest]) (*connect.Response[v1.ScanResponse], error)
+	// Watch streams the changes committed to the requested key or prefix, as they are flushed by the injector.
+	Watch(context.Context, *connect.Request[v1.WatchRequest]) (*connect.ServerStreamForClient[v1.WatchResponse], error)
+	// ReadChanges returns the next _limit_ change log entries starting at the given sequence, requires the sink to run with the change log.
+	ReadChanges(context.Context, *connect.Request[v1.ReadChangesRequest]) (*connect.Response[v1.ReadChangesResponse], error)
+	// QueryIndex returns the next _limit_ keys whose value has the requested value in the requested index if any exist, not found error code otherwise.
+	QueryIndex(context.Context, *connect.Request[v1.QueryIndexRequest]) (*connect.Response[v1.QueryIndexResponse], error)
+	// DescribeValueTypes returns the types of the values declared in the sink config, along the proto files defining them.
+	DescribeValueTypes(context.Context, *connect.Request[v1.DescribeValueTypesRequest]) (*connect.Response[v1.DescribeValueTypesResponse], error)
+	// Count returns the number of keys that match the requested prefix, or that are in the requested range if no prefix is given.
+	Count(context.Context, *connect.Request[v1.CountRequest]) (*connect.Response[v1.CountResponse], error)
 }
 
 // NewKvClient constructs a client for the sf.substreams.sink.kv.v1.Kv service. By default, it uses
@@ -98,15 +123,50 @@ func NewKvClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.
 			connect.WithSchema(kvScanMethodDescriptor),
 			connect.WithClientOptions(opts...),
 		),
+		watch: connect.NewClient[v1.WatchRequest, v1.WatchResponse](
+			httpClient,
+			baseURL+KvWatchProcedure,
+			connect.WithSchema(kvWatchMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		readChanges: connect.NewClient[v1.ReadChangesRequest, v1.ReadChangesResponse](
+			httpClient,
+			baseURL+KvReadChangesProcedure,
+			connect.WithSchema(kvReadChangesMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		queryIndex: connect.NewClient[v1.QueryIndexRequest, v1.QueryIndexResponse](
+			httpClient,
+			baseURL+KvQueryIndexProcedure,
+			connect.WithSchema(kvQueryIndexMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		describeValueTypes: connect.NewClient[v1.DescribeValueTypesRequest, v1.DescribeValueTypesResponse](
+			httpClient,
+			baseURL+KvDescribeValueTypesProcedure,
+			connect.WithSchema(kvDescribeValueTypesMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		count: connect.NewClient[v1.CountRequest, v1.CountResponse](
+			httpClient,
+			baseURL+KvCountProcedure,
+			connect.WithSchema(kvCountMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // kvClient implements KvClient.
 type kvClient struct {
-	get         *connect.Client[v1.GetRequest, v1.GetResponse]
-	getMany     *connect.Client[v1.GetManyRequest, v1.GetManyResponse]
-	getByPrefix *connect.Client[v1.GetByPrefixRequest, v1.GetByPrefixResponse]
-	scan        *connect.Client[v1.ScanRequest, v1.ScanResponse]
+	get                *connect.Client[v1.GetRequest, v1.GetResponse]
+	getMany            *connect.Client[v1.GetManyRequest, v1.GetManyResponse]
+	getByPrefix        *connect.Client[v1.GetByPrefixRequest, v1.GetByPrefixResponse]
+	scan               *connect.Client[v1.ScanRequest, v1.ScanResponse]
+	watch              *connect.Client[v1.WatchRequest, v1.WatchResponse]
+	readChanges        *connect.Client[v1.ReadChangesRequest, v1.ReadChangesResponse]
+	queryIndex         *connect.Client[v1.QueryIndexRequest, v1.QueryIndexResponse]
+	describeValueTypes *connect.Client[v1.DescribeValueTypesRequest, v1.DescribeValueTypesResponse]
+	count              *connect.Client[v1.CountRequest, v1.CountResponse]
 }
 
 // Get calls sf.substreams.sink.kv.v1.Kv.Get.
@@ -129,16 +189,51 @@ func (c *kvClient) Scan(ctx context.Context, req *connect.Request[v1.ScanRequest
 	return c.scan.CallUnary(ctx, req)
 }
 
+// Watch calls sf.substreams.sink.kv.v1.Kv.Watch.
+func (c *kvClient) Watch(ctx context.Context, req *connect.Request[v1.WatchRequest]) (*connect.ServerStreamForClient[v1.WatchResponse], error) {
+	return c.watch.CallServerStream(ctx, req)
+}
+
+// ReadChanges calls sf.substreams.sink.kv.v1.Kv.ReadChanges.
+func (c *kvClient) ReadChanges(ctx context.Context, req *connect.Request[v1.ReadChangesRequest]) (*connect.Response[v1.ReadChangesResponse], error) {
+	return c.readChanges.CallUnary(ctx, req)
+}
+
+// QueryIndex calls sf.substreams.sink.kv.v1.Kv.QueryIndex.
+func (c *kvClient) QueryIndex(ctx context.Context, req *connect.Request[v1.QueryIndexRequest]) (*connect.Response[v1.QueryIndexResponse], error) {
+	return c.queryIndex.CallUnary(ctx, req)
+}
+
+// DescribeValueTypes calls sf.substreams.sink.kv.v1.Kv.DescribeValueTypes.
+func (c *kvClient) DescribeValueTypes(ctx context.Context, req *connect.Request[v1.DescribeValueTypesRequest]) (*connect.Response[v1.DescribeValueTypesResponse], error) {
+	return c.describeValueTypes.CallUnary(ctx, req)
+}
+
+// Count calls sf.substreams.sink.kv.v1.Kv.Count.
+func (c *kvClient) Count(ctx context.Context, req *connect.Request[v1.CountRequest]) (*connect.Response[v1.CountResponse], error) {
+	return c.count.CallUnary(ctx, req)
+}
+
 // KvHandler is an implementation of the sf.substreams.sink.kv.v1.Kv service.
 type KvHandler interface {
 	// Get returns the requested value as bytes if it exists, not found error code otherwise.
 	Get(context.Context, *connect.Request[v1.GetRequest]) (*connect.Response[v1.GetResponse], error)
-	// GetMany returns the requested values as bytes if all of them exists, not found error code otherwise.
+	// GetMany returns one entry per requested key, in request order, flagging whether each key was found.
 	GetMany(context.Context, *connect.Request[v1.GetManyRequest]) (*connect.Response[v1.GetManyResponse], error)
 	// GetByPrefix returns the next _limit_ key/value pair that match the requested prefix if any exist, not found error code otherwise.
 	GetByPrefix(context.Context, *connect.Request[v1.GetByPrefixRequest]) (*connect.Response[v1.GetByPrefixResponse], error)
 	// Scan returns then next _limit_ key/value pairs starting lexicographically at the given key, not found error code otherwise.
 	Scan(context.Context, *connect.Request[v1.ScanRequest]) (*connect.Response[v1.ScanResponse], error)
+	// Watch streams the changes committed to the requested key or prefix, as they are flushed by the injector.
+	Watch(context.Context, *connect.Request[v1.WatchRequest], *connect.ServerStream[v1.WatchResponse]) error
+	// ReadChanges returns the next _limit_ change log entries starting at the given sequence, requires the sink to run with the change log.
+	ReadChanges(context.Context, *connect.Request[v1.ReadChangesRequest]) (*connect.Response[v1.ReadChangesResponse], error)
+	// QueryIndex returns the next _limit_ keys whose value has the requested value in the requested index if any exist, not found error code otherwise.
+	QueryIndex(context.Context, *connect.Request[v1.QueryIndexRequest]) (*connect.Response[v1.QueryIndexResponse], error)
+	// DescribeValueTypes returns the types of the values declared in the sink config, along the proto files defining them.
+	DescribeValueTypes(context.Context, *connect.Request[v1.DescribeValueTypesRequest]) (*connect.Response[v1.DescribeValueTypesResponse], error)
+	// Count returns the number of keys that match the requested prefix, or that are in the requested range if no prefix is given.
+	Count(context.Context, *connect.Request[v1.CountRequest]) (*connect.Response[v1.CountResponse], error)
 }
 
 // NewKvHandler builds an HTTP handler from the service implementation. It returns the path on which
@@ -171,6 +266,36 @@ func NewKvHandler(svc KvHandler, opts ...connect.HandlerOption) (string, http.Ha
 		connect.WithSchema(kvScanMethodDescriptor),
 		connect.WithHandlerOptions(opts...),
 	)
+	kvWatchHandler := connect.NewServerStreamHandler(
+		KvWatchProcedure,
+		svc.Watch,
+		connect.WithSchema(kvWatchMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	kvReadChangesHandler := connect.NewUnaryHandler(
+		KvReadChangesProcedure,
+		svc.ReadChanges,
+		connect.WithSchema(kvReadChangesMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	kvQueryIndexHandler := connect.NewUnaryHandler(
+		KvQueryIndexProcedure,
+		svc.QueryIndex,
+		connect.WithSchema(kvQueryIndexMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	kvDescribeValueTypesHandler := connect.NewUnaryHandler(
+		KvDescribeValueTypesProcedure,
+		svc.DescribeValueTypes,
+		connect.WithSchema(kvDescribeValueTypesMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	kvCountHandler := connect.NewUnaryHandler(
+		KvCountProcedure,
+		svc.Count,
+		connect.WithSchema(kvCountMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/sf.substreams.sink.kv.v1.Kv/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case KvGetProcedure:
@@ -181,6 +306,16 @@ func NewKvHandler(svc KvHandler, opts ...connect.HandlerOption) (string, http.Ha
 			kvGetByPrefixHandler.ServeHTTP(w, r)
 		case KvScanProcedure:
 			kvScanHandler.ServeHTTP(w, r)
+		case KvWatchProcedure:
+			kvWatchHandler.ServeHTTP(w, r)
+		case KvReadChangesProcedure:
+			kvReadChangesHandler.ServeHTTP(w, r)
+		case KvQueryIndexProcedure:
+			kvQueryIndexHandler.ServeHTTP(w, r)
+		case KvDescribeValueTypesProcedure:
+			kvDescribeValueTypesHandler.ServeHTTP(w, r)
+		case KvCountProcedure:
+			kvCountHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -205,3 +340,23 @@ func (UnimplementedKvHandler) GetByPrefix(context.Context, *connect.Request[v1.G
 func (UnimplementedKvHandler) Scan(context.Context, *connect.Request[v1.ScanRequest]) (*connect.Response[v1.ScanResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("sf.substreams.sink.kv.v1.Kv.Scan is not implemented"))
 }
+
+func (UnimplementedKvHandler) Watch(context.Context, *connect.Request[v1.WatchRequest], *connect.ServerStream[v1.WatchResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("sf.substreams.sink.kv.v1.Kv.Watch is not implemented"))
+}
+
+func (UnimplementedKvHandler) ReadChanges(context.Context, *connect.Request[v1.ReadChangesRequest]) (*connect.Response[v1.ReadChangesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("sf.substreams.sink.kv.v1.Kv.ReadChanges is not implemented"))
+}
+
+func (UnimplementedKvHandler) QueryIndex(context.Context, *connect.Request[v1.QueryIndexRequest]) (*connect.Response[v1.QueryIndexResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("sf.substreams.sink.kv.v1.Kv.QueryIndex is not implemented"))
+}
+
+func (UnimplementedKvHandler) DescribeValueTypes(context.Context, *connect.Request[v1.DescribeValueTypesRequest]) (*connect.Response[v1.DescribeValueTypesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("sf.substreams.sink.kv.v1.Kv.DescribeValueTypes is not implemented"))
+}
+
+func (UnimplementedKvHandler) Count(context.Context, *connect.Request[v1.CountRequest]) (*connect.Response[v1.CountResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("sf.substreams.sink.kv.v1.Kv.Count is not implemented"))
+}
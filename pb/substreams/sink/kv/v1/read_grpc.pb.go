@@ -24,12 +24,22 @@ const _ = grpc.SupportPackageIsVersion7
 type KvClient interface {
 	// Get returns the requested value as bytes if it exists, not found error code otherwise.
 	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
-	// GetMany returns the requested values as bytes if all of them exists, not found error code otherwise.
+	// GetMany returns one entry per requested key, in request order, flagging whether each key was found.
 	GetMany(ctx context.Context, in *GetManyRequest, opts ...grpc.CallOption) (*GetManyResponse, error)
 	// GetByPrefix returns the next _limit_ key/value pair that match the requested prefix if any exist, not found error code otherwise.
 	GetByPrefix(ctx context.Context, in *GetByPrefixRequest, opts ...grpc.CallOption) (*GetByPrefixResponse, error)
 	// Scan returns then next _limit_ key/value pairs starting lexicographically at the given key, not found error code otherwise.
 	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanResponse, error)
+	// Watch streams the changes committed to the requested key or prefix, as they are flushed by the injector.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Kv_WatchClient, error)
+	// ReadChanges returns the next _limit_ change log entries starting at the given sequence, requires the sink to run with the change log.
+	ReadChanges(ctx context.Context, in *ReadChangesRequest, opts ...grpc.CallOption) (*ReadChangesResponse, error)
+	// QueryIndex returns the next _limit_ keys whose value has the requested value in the requested index if any exist, not found error code otherwise.
+	QueryIndex(ctx context.Context, in *QueryIndexRequest, opts ...grpc.CallOption) (*QueryIndexResponse, error)
+	// DescribeValueTypes returns the types of the values declared in the sink config, along the proto files defining them.
+	DescribeValueTypes(ctx context.Context, in *DescribeValueTypesRequest, opts ...grpc.CallOption) (*DescribeValueTypesResponse, error)
+	// Count returns the number of keys that match the requested prefix, or that are in the requested range if no prefix is given.
+	Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error)
 }
 
 type kvClient struct {
@@ -76,18 +86,96 @@ func (c *kvClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallO
 	return out, nil
 }
 
+func (c *kvClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Kv_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Kv_ServiceDesc.Streams[0], "/sf.substreams.sink.kv.v1.Kv/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kvWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Kv_WatchClient interface {
+	Recv() (*WatchResponse, error)
+	grpc.ClientStream
+}
+
+type kvWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *kvWatchClient) Recv() (*WatchResponse, error) {
+	m := new(WatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *kvClient) ReadChanges(ctx context.Context, in *ReadChangesRequest, opts ...grpc.CallOption) (*ReadChangesResponse, error) {
+	out := new(ReadChangesResponse)
+	err := c.cc.Invoke(ctx, "/sf.substreams.sink.kv.v1.Kv/ReadChanges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kvClient) QueryIndex(ctx context.Context, in *QueryIndexRequest, opts ...grpc.CallOption) (*QueryIndexResponse, error) {
+	out := new(QueryIndexResponse)
+	err := c.cc.Invoke(ctx, "/sf.substreams.sink.kv.v1.Kv/QueryIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kvClient) DescribeValueTypes(ctx context.Context, in *DescribeValueTypesRequest, opts ...grpc.CallOption) (*DescribeValueTypesResponse, error) {
+	out := new(DescribeValueTypesResponse)
+	err := c.cc.Invoke(ctx, "/sf.substreams.sink.kv.v1.Kv/DescribeValueTypes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kvClient) Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/sf.substreams.sink.kv.v1.Kv/Count", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KvServer is the server API for Kv service.
 // All implementations should embed UnimplementedKvServer
 // for forward compatibility
 type KvServer interface {
 	// Get returns the requested value as bytes if it exists, not found error code otherwise.
 	Get(context.Context, *GetRequest) (*GetResponse, error)
-	// GetMany returns the requested values as bytes if all of them exists, not found error code otherwise.
+	// GetMany returns one entry per requested key, in request order, flagging whether each key was found.
 	GetMany(context.Context, *GetManyRequest) (*GetManyResponse, error)
 	// GetByPrefix returns the next _limit_ key/value pair that match the requested prefix if any exist, not found error code otherwise.
 	GetByPrefix(context.Context, *GetByPrefixRequest) (*GetByPrefixResponse, error)
 	// Scan returns then next _limit_ key/value pairs starting lexicographically at the given key, not found error code otherwise.
 	Scan(context.Context, *ScanRequest) (*ScanResponse, error)
+	// Watch streams the changes committed to the requested key or prefix, as they are flushed by the injector.
+	Watch(*WatchRequest, Kv_WatchServer) error
+	// ReadChanges returns the next _limit_ change log entries starting at the given sequence, requires the sink to run with the change log.
+	ReadChanges(context.Context, *ReadChangesRequest) (*ReadChangesResponse, error)
+	// QueryIndex returns the next _limit_ keys whose value has the requested value in the requested index if any exist, not found error code otherwise.
+	QueryIndex(context.Context, *QueryIndexRequest) (*QueryIndexResponse, error)
+	// DescribeValueTypes returns the types of the values declared in the sink config, along the proto files defining them.
+	DescribeValueTypes(context.Context, *DescribeValueTypesRequest) (*DescribeValueTypesResponse, error)
+	// Count returns the number of keys that match the requested prefix, or that are in the requested range if no prefix is given.
+	Count(context.Context, *CountRequest) (*CountResponse, error)
 }
 
 // UnimplementedKvServer should be embedded to have forward compatible implementations.
@@ -106,6 +194,21 @@ func (UnimplementedKvServer) GetByPrefix(context.Context, *GetByPrefixRequest) (
 func (UnimplementedKvServer) Scan(context.Context, *ScanRequest) (*ScanResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
 }
+func (UnimplementedKvServer) Watch(*WatchRequest, Kv_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedKvServer) ReadChanges(context.Context, *ReadChangesRequest) (*ReadChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadChanges not implemented")
+}
+func (UnimplementedKvServer) QueryIndex(context.Context, *QueryIndexRequest) (*QueryIndexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryIndex not implemented")
+}
+func (UnimplementedKvServer) DescribeValueTypes(context.Context, *DescribeValueTypesRequest) (*DescribeValueTypesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeValueTypes not implemented")
+}
+func (UnimplementedKvServer) Count(context.Context, *CountRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Count not implemented")
+}
 
 // UnsafeKvServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to KvServer will
@@ -190,6 +293,99 @@ func _Kv_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Kv_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KvServer).Watch(m, &kvWatchServer{stream})
+}
+
+type Kv_WatchServer interface {
+	Send(*WatchResponse) error
+	grpc.ServerStream
+}
+
+type kvWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *kvWatchServer) Send(m *WatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Kv_ReadChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).ReadChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sf.substreams.sink.kv.v1.Kv/ReadChanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).ReadChanges(ctx, req.(*ReadChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kv_QueryIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).QueryIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sf.substreams.sink.kv.v1.Kv/QueryIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).QueryIndex(ctx, req.(*QueryIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kv_DescribeValueTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeValueTypesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).DescribeValueTypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sf.substreams.sink.kv.v1.Kv/DescribeValueTypes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).DescribeValueTypes(ctx, req.(*DescribeValueTypesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kv_Count_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).Count(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sf.substreams.sink.kv.v1.Kv/Count",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).Count(ctx, req.(*CountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Kv_ServiceDesc is the grpc.ServiceDesc for Kv service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -213,7 +409,29 @@ var Kv_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Scan",
 			Handler:    _Kv_Scan_Handler,
 		},
+		{
+			MethodName: "ReadChanges",
+			Handler:    _Kv_ReadChanges_Handler,
+		},
+		{
+			MethodName: "QueryIndex",
+			Handler:    _Kv_QueryIndex_Handler,
+		},
+		{
+			MethodName: "DescribeValueTypes",
+			Handler:    _Kv_DescribeValueTypes_Handler,
+		},
+		{
+			MethodName: "Count",
+			Handler:    _Kv_Count_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Kv_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "substreams/sink/kv/v1/read.proto",
 }
@@ -0,0 +1,99 @@
+// Package protofiles resolves the descriptors of the proto files packaged in a spkg.
+package protofiles
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// New builds the descriptors of the spkg proto files. Imports that are not
+// packaged in the spkg are resolved against the files linked in this binary, this is
+// typically the case of the well-known types.
+func New(protoFiles []*descriptorpb.FileDescriptorProto) (*protoregistry.Files, error) {
+	byPath := make(map[string]*descriptorpb.FileDescriptorProto, len(protoFiles))
+	for _, file := range protoFiles {
+		byPath[file.GetName()] = file
+	}
+
+	files := new(protoregistry.Files)
+	resolver := &fallbackResolver{files}
+
+	var register func(path string) error
+	register = func(path string) error {
+		if _, err := resolver.FindFileByPath(path); err == nil {
+			return nil
+		}
+
+		file, found := byPath[path]
+		if !found {
+			return fmt.Errorf("proto file %q is not part of the spkg", path)
+		}
+
+		for _, dependency := range file.Dependency {
+			if err := register(dependency); err != nil {
+				return fmt.Errorf("dependency of %q: %w", path, err)
+			}
+		}
+
+		desc, err := protodesc.NewFile(file, resolver)
+		if err != nil {
+			return fmt.Errorf("building proto file %q: %w", path, err)
+		}
+		return files.RegisterFile(desc)
+	}
+
+	for _, file := range protoFiles {
+		if err := register(file.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// FindMessage finds the message named name in files, or in the files linked in this
+// binary like New does for imports.
+func FindMessage(files *protoregistry.Files, name string) (protoreflect.MessageDescriptor, error) {
+	desc, err := (&fallbackResolver{files}).FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find %q in spkg proto files", name)
+	}
+
+	message, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message", name)
+	}
+	return message, nil
+}
+
+// NewResolver resolves descriptors against files, or against the files linked in this
+// binary like New does for imports.
+func NewResolver(files *protoregistry.Files) protodesc.Resolver {
+	return &fallbackResolver{files}
+}
+
+// fallbackResolver resolves against files first and against the global registry
+// afterwards.
+type fallbackResolver struct {
+	files *protoregistry.Files
+}
+
+func (r *fallbackResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	file, err := r.files.FindFileByPath(path)
+	if errors.Is(err, protoregistry.NotFound) {
+		return protoregistry.GlobalFiles.FindFileByPath(path)
+	}
+	return file, err
+}
+
+func (r *fallbackResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	desc, err := r.files.FindDescriptorByName(name)
+	if errors.Is(err, protoregistry.NotFound) {
+		return protoregistry.GlobalFiles.FindDescriptorByName(name)
+	}
+	return desc, err
+}
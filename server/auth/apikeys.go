@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// APIKeys authenticates requests carrying one of a static set of API keys, either as
+// an `Authorization: Bearer <key>` or an `X-Api-Key: <key>` header.
+type APIKeys struct {
+	byHash map[[sha256.Size]byte]*Principal
+}
+
+// apiKeysFile is the format of the API keys file:
+//
+//	{"keys": [{"name": "explorer", "key": "<secret>", "prefixes": ["account:", "block:"]}]}
+type apiKeysFile struct {
+	Keys []struct {
+		Name     string   `json:"name"`
+		Key      string   `json:"key"`
+		Prefixes []string `json:"prefixes"`
+	} `json:"keys"`
+}
+
+// LoadAPIKeys reads the API keys file at path, see apiKeysFile for its format.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading API keys file: %w", err)
+	}
+
+	var file apiKeysFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("decoding API keys file %q: %w", path, err)
+	}
+
+	keys := &APIKeys{byHash: make(map[[sha256.Size]byte]*Principal, len(file.Keys))}
+	for i, key := range file.Keys {
+		if key.Name == "" || key.Key == "" {
+			return nil, fmt.Errorf("API key #%d of %q: 'name' and 'key' are required", i, path)
+		}
+		hash := sha256.Sum256([]byte(key.Key))
+		if _, found := keys.byHash[hash]; found {
+			return nil, fmt.Errorf("API key %q of %q: key is used more than once", key.Name, path)
+		}
+		keys.byHash[hash] = &Principal{Name: key.Name, Prefixes: key.Prefixes}
+	}
+	return keys, nil
+}
+
+func (k *APIKeys) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	key := header.Get("X-Api-Key")
+	if key == "" {
+		key, _ = bearerToken(header)
+	}
+	if key == "" {
+		return nil, ErrNotRecognized
+	}
+
+	// keys are looked up by hash so that the lookup time does not depend on the secrets
+	principal, found := k.byHash[sha256.Sum256([]byte(key))]
+	if !found {
+		if header.Get("X-Api-Key") != "" {
+			return nil, errors.New("unknown API key")
+		}
+		// the bearer token may be a JWT for the next authenticator
+		return nil, ErrNotRecognized
+	}
+	return principal, nil
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+)
+
+// ErrNotRecognized is returned by an Authenticator when the request carries no
+// credentials of its kind, Authenticators then trying the next one.
+var ErrNotRecognized = errors.New("credentials not recognized")
+
+// Principal is who a request was authenticated as.
+type Principal struct {
+	// Name identifies the principal in logs and errors, the API key name or the JWT subject
+	Name string
+
+	// Prefixes are the key prefixes the principal may read, the empty prefix granting
+	// access to every key. A principal without prefixes cannot read any key.
+	Prefixes []string
+}
+
+// CanRead reports whether key starts with one of the prefixes of the principal.
+func (p *Principal) CanRead(key string) bool {
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadPrefix reports whether every key starting with prefix can be read.
+func (p *Principal) CanReadPrefix(prefix string) bool {
+	return p.CanRead(prefix)
+}
+
+// CanReadRange reports whether every key of [begin, exclusiveEnd) can be read, an empty
+// exclusiveEnd being open-ended. The range must lie within a single prefix.
+func (p *Principal) CanReadRange(begin, exclusiveEnd string) bool {
+	for _, prefix := range p.Prefixes {
+		if !strings.HasPrefix(begin, prefix) {
+			continue
+		}
+		end, bounded := prefixEnd(prefix)
+		if !bounded || (exclusiveEnd != "" && exclusiveEnd <= end) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadAll reports whether the principal may read every key, required by the reads
+// spanning the whole keyspace like ReadChanges.
+func (p *Principal) CanReadAll() bool {
+	for _, prefix := range p.Prefixes {
+		if prefix == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixEnd returns the smallest key greater than all the keys starting with prefix,
+// bounded being false when there is none.
+func prefixEnd(prefix string) (end string, bounded bool) {
+	out := []byte(prefix)
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i] < 0xff {
+			out[i]++
+			return string(out[:i+1]), true
+		}
+	}
+	return "", false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying principal, see PrincipalFromContext.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal the request of ctx was authenticated as,
+// nil if it was not.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// Authenticator authenticates a request from its headers.
+type Authenticator interface {
+	Authenticate(ctx context.Context, header http.Header) (*Principal, error)
+}
+
+// Authenticators tries each of its authenticators in turn, the first one recognizing the
+// credentials of the request deciding.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	for _, authenticator := range a {
+		principal, err := authenticator.Authenticate(ctx, header)
+		if errors.Is(err, ErrNotRecognized) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, errors.New("missing or unknown credentials")
+}
+
+// bearerToken returns the token of an `Authorization: Bearer <token>` header.
+func bearerToken(header http.Header) (string, bool) {
+	value := header.Get("Authorization")
+	if len(value) < 7 || !strings.EqualFold(value[:7], "bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(value[7:])
+	return token, token != ""
+}
+
+// Interceptor authenticates the requests of a Connect handler, failing them with
+// CodeUnauthenticated, and makes their principal available through
+// PrincipalFromContext. Authorization is left to the handler.
+type Interceptor struct {
+	authenticator Authenticator
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+func NewInterceptor(authenticator Authenticator) *Interceptor {
+	return &Interceptor{authenticator: authenticator}
+}
+
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, err := i.authenticate(ctx, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.authenticate(ctx, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context, header http.Header) (context.Context, error) {
+	principal, err := i.authenticator.Authenticate(ctx, header)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
+	return WithPrincipal(ctx, principal), nil
+}
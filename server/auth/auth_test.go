@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func TestPrincipal(t *testing.T) {
+	principal := &Principal{Name: "test", Prefixes: []string{"account:", "block:1", "\xff"}}
+
+	assert.True(t, principal.CanRead("account:1"))
+	assert.True(t, principal.CanRead("block:12"))
+	assert.False(t, principal.CanRead("block:2"))
+	assert.False(t, principal.CanRead("accounts"))
+
+	assert.True(t, principal.CanReadPrefix("account:"))
+	assert.True(t, principal.CanReadPrefix("account:12"))
+	assert.False(t, principal.CanReadPrefix("account"))
+	assert.False(t, principal.CanReadPrefix(""))
+
+	assert.True(t, principal.CanReadRange("account:1", "account:2"))
+	assert.True(t, principal.CanReadRange("account:", "account;"))
+	assert.False(t, principal.CanReadRange("account:", "account;0"))
+	assert.False(t, principal.CanReadRange("account:", ""))
+	assert.False(t, principal.CanReadRange("account", "account:2"))
+	assert.True(t, principal.CanReadRange("\xff\x01", ""))
+
+	assert.False(t, principal.CanReadAll())
+	assert.True(t, (&Principal{Prefixes: []string{""}}).CanReadAll())
+	assert.True(t, (&Principal{Prefixes: []string{""}}).CanReadRange("", ""))
+	assert.False(t, (&Principal{}).CanRead("a"))
+}
+
+func TestAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": [
+		{"name": "explorer", "key": "secret-1", "prefixes": ["account:"]},
+		{"name": "admin", "key": "secret-2", "prefixes": [""]}
+	]}`), 0600))
+
+	keys, err := LoadAPIKeys(path)
+	require.NoError(t, err)
+
+	header := func(name, value string) http.Header {
+		header := http.Header{}
+		header.Set(name, value)
+		return header
+	}
+
+	principal, err := keys.Authenticate(context.Background(), header("X-Api-Key", "secret-1"))
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "explorer", Prefixes: []string{"account:"}}, principal)
+
+	principal, err = keys.Authenticate(context.Background(), header("Authorization", "Bearer secret-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "admin", principal.Name)
+
+	_, err = keys.Authenticate(context.Background(), header("X-Api-Key", "unknown"))
+	assert.EqualError(t, err, "unknown API key")
+
+	_, err = keys.Authenticate(context.Background(), header("Authorization", "Bearer unknown"))
+	assert.Equal(t, ErrNotRecognized, err)
+
+	_, err = keys.Authenticate(context.Background(), http.Header{})
+	assert.Equal(t, ErrNotRecognized, err)
+
+	_, err = Authenticators{keys}.Authenticate(context.Background(), header("Authorization", "Bearer unknown"))
+	assert.EqualError(t, err, "missing or unknown credentials")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": [{"name": "a", "key": "same"}, {"name": "b", "key": "same"}]}`), 0600))
+	_, err = LoadAPIKeys(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtLeeway is the clock skew tolerated on the `exp` and `nbf` claims.
+const jwtLeeway = time.Minute
+
+// JWT authenticates requests carrying an `Authorization: Bearer <token>` header whose
+// token is a JWT signed by one of the keys of a local JWKS file. Tokens must have an
+// `exp` claim and a non-empty `sub` one, the prefixes they may read are taken from
+// PrefixesClaim.
+type JWT struct {
+	keys []*jwk
+
+	// Issuer and Audience, if set, must match the `iss` and `aud` claims
+	Issuer   string
+	Audience string
+
+	// PrefixesClaim is the claim listing the key prefixes the token may read
+	PrefixesClaim string
+
+	now func() time.Time
+}
+
+// DefaultPrefixesClaim is the default JWT.PrefixesClaim.
+const DefaultPrefixesClaim = "kv_prefixes"
+
+type jwk struct {
+	id        string
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// LoadJWT reads the JWKS file at path, the RSA, EC (P-256, P-384 and P-521) and
+// Ed25519 keys being used to verify tokens.
+func LoadJWT(path string, issuer, audience string) (*JWT, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("decoding JWKS file %q: %w", path, err)
+	}
+
+	j := &JWT{Issuer: issuer, Audience: audience, PrefixesClaim: DefaultPrefixesClaim, now: time.Now}
+	for i, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var publicKey crypto.PublicKey
+		switch key.Kty {
+		case "RSA":
+			n, errN := decodeBigInt(key.N)
+			e, errE := decodeBigInt(key.E)
+			if errN != nil || errE != nil || !e.IsInt64() {
+				return nil, fmt.Errorf("JWKS key #%d of %q: invalid RSA key", i, path)
+			}
+			publicKey = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			curve, found := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}[key.Crv]
+			x, errX := decodeBigInt(key.X)
+			y, errY := decodeBigInt(key.Y)
+			if !found || errX != nil || errY != nil || !curve.IsOnCurve(x, y) {
+				return nil, fmt.Errorf("JWKS key #%d of %q: invalid EC key", i, path)
+			}
+			publicKey = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		case "OKP":
+			x, err := base64.RawURLEncoding.DecodeString(key.X)
+			if key.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("JWKS key #%d of %q: invalid OKP key, only Ed25519 is supported", i, path)
+			}
+			publicKey = ed25519.PublicKey(x)
+		default:
+			return nil, fmt.Errorf("JWKS key #%d of %q: unsupported key type %q", i, path, key.Kty)
+		}
+		j.keys = append(j.keys, &jwk{id: key.Kid, algorithm: key.Alg, key: publicKey})
+	}
+	if len(j.keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %q has no signing key", path)
+	}
+	return j, nil
+}
+
+func (j *JWT) Authenticate(ctx context.Context, header http.Header) (*Principal, error) {
+	token, found := bearerToken(header)
+	if !found || strings.Count(token, ".") != 2 {
+		return nil, ErrNotRecognized
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtAlgorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+		jwt.WithTimeFunc(j.now),
+	}
+	if j.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.Issuer))
+	}
+	if j.Audience != "" {
+		opts = append(opts, jwt.WithAudience(j.Audience))
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.NewParser(opts...).ParseWithClaims(token, claims, j.verificationKeys); err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	return j.principal(claims)
+}
+
+// jwtAlgorithms are the accepted `alg` of the tokens. "none" and the HMAC algorithms are
+// never accepted, the keys being public.
+var jwtAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// verificationKeys returns the keys matching the `kid` and `alg` of token, keys without
+// `kid` or `alg` in the JWKS matching any.
+func (j *JWT) verificationKeys(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	set := jwt.VerificationKeySet{}
+	for _, key := range j.keys {
+		if kid != "" && key.id != "" && kid != key.id {
+			continue
+		}
+		if key.algorithm != "" && key.algorithm != token.Method.Alg() {
+			continue
+		}
+		set.Keys = append(set.Keys, key.key)
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("no key with id %q for algorithm %q", kid, token.Method.Alg())
+	}
+	return set, nil
+}
+
+func (j *JWT) principal(claims jwt.MapClaims) (*Principal, error) {
+	subject, err := claims.GetSubject()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	if subject == "" {
+		// the subject names the principal, e.g. for rate limiting
+		return nil, fmt.Errorf("invalid JWT: %w: sub claim is required", jwt.ErrTokenRequiredClaimMissing)
+	}
+	principal := &Principal{Name: subject}
+	if value, found := claims[j.PrefixesClaim]; found {
+		// round-tripped through JSON to accept the same values as the other claims
+		content, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(content, &principal.Prefixes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT: claim %q must be a list of key prefixes", j.PrefixesClaim)
+		}
+	}
+	return principal, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(content), nil
+}
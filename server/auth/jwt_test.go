@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func TestJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encode := func(content []byte) string { return base64.RawURLEncoding.EncodeToString(content) }
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": encode(edPublic)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": encode(otherKey.N.Bytes()), "e": "AQAB"},
+	}}
+	content, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, content, 0600))
+
+	j, err := LoadJWT(path, "issuer", "kv")
+	require.NoError(t, err)
+	require.Len(t, j.keys, 3)
+	now := time.Unix(1_700_000_000, 0)
+	j.now = func() time.Time { return now }
+
+	sign := func(alg, kid string, key interface{}, claims map[string]interface{}) string {
+		header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+		require.NoError(t, err)
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+		signed := encode(header) + "." + encode(payload)
+
+		var signature []byte
+		switch alg {
+		case "RS256":
+			sum := crypto.SHA256.New()
+			sum.Write([]byte(signed))
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, sum.Sum(nil))
+		case "ES256":
+			sum := crypto.SHA256.New()
+			sum.Write([]byte(signed))
+			var r, s *big.Int
+			r, s, err = ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), sum.Sum(nil))
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		case "EdDSA":
+			signature = ed25519.Sign(key.(ed25519.PrivateKey), []byte(signed))
+		case "HS256":
+			mac := hmac.New(sha256.New, key.([]byte))
+			mac.Write([]byte(signed))
+			signature = mac.Sum(nil)
+		}
+		require.NoError(t, err)
+		return signed + "." + encode(signature)
+	}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		out := map[string]interface{}{
+			"sub":         "explorer",
+			"iss":         "issuer",
+			"aud":         []string{"other", "kv"},
+			"exp":         now.Add(time.Hour).Unix(),
+			"kv_prefixes": []string{"account:"},
+		}
+		for key, value := range overrides {
+			if value == nil {
+				delete(out, key)
+				continue
+			}
+			out[key] = value
+		}
+		return out
+	}
+	authenticate := func(token string) (*Principal, error) {
+		header := http.Header{}
+		header.Set("Authorization", "Bearer "+token)
+		return j.Authenticate(context.Background(), header)
+	}
+
+	expected := &Principal{Name: "explorer", Prefixes: []string{"account:"}}
+	for _, token := range []string{
+		sign("RS256", "rsa", rsaKey, claims(nil)),
+		sign("ES256", "ec", ecKey, claims(nil)),
+		sign("EdDSA", "ed", edKey, claims(nil)),
+		sign("EdDSA", "", edKey, claims(map[string]interface{}{"aud": "kv"})),
+	} {
+		principal, err := authenticate(token)
+		require.NoError(t, err)
+		assert.Equal(t, expected, principal)
+	}
+
+	principal, err := authenticate(sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"kv_prefixes": nil})))
+	require.NoError(t, err)
+	assert.Empty(t, principal.Prefixes)
+
+	_, err = authenticate(sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"kv_prefixes": "account:"})))
+	assert.EqualError(t, err, `invalid JWT: claim "kv_prefixes" must be a list of key prefixes`)
+
+	// the PEM encoded public key, used as the HMAC secret by the algorithm confusion attacks
+	publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr error
+	}{
+		{"expired", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})), jwt.ErrTokenExpired},
+		{"missing exp", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})), jwt.ErrTokenRequiredClaimMissing},
+		{"missing sub", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"sub": nil})), jwt.ErrTokenRequiredClaimMissing},
+		{"empty sub", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"sub": ""})), jwt.ErrTokenRequiredClaimMissing},
+		{"not before", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()})), jwt.ErrTokenNotValidYet},
+		{"issuer", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "other"})), jwt.ErrTokenInvalidIssuer},
+		{"audience", sign("RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other"})), jwt.ErrTokenInvalidAudience},
+		{"unknown key", sign("RS256", "rsa", otherKey, claims(nil)), jwt.ErrTokenSignatureInvalid},
+		{"unknown kid", sign("RS256", "missing", rsaKey, claims(nil)), jwt.ErrTokenUnverifiable},
+		{"encryption key", sign("RS256", "enc", otherKey, claims(nil)), jwt.ErrTokenUnverifiable},
+		{"algorithm mismatch", sign("RS256", "ec", rsaKey, claims(nil)), jwt.ErrTokenSignatureInvalid},
+		{"alg none", encode([]byte(`{"alg":"none","kid":"rsa"}`)) + "." + encode(mustJSON(t, claims(nil))) + ".", jwt.ErrTokenSignatureInvalid},
+		{"alg HS256", encode([]byte(`{"alg":"HS256"}`)) + "." + encode([]byte(`{"exp":9999999999}`)) + "." + encode([]byte("mac")), jwt.ErrTokenSignatureInvalid},
+		{"alg HS256 with public key", sign("HS256", "rsa", publicKeyPEM, claims(nil)), jwt.ErrTokenSignatureInvalid},
+		{"alg HS256 with public key without kid", sign("HS256", "", publicKeyPEM, claims(nil)), jwt.ErrTokenSignatureInvalid},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principal, err := authenticate(test.token)
+			require.Error(t, err)
+			assert.Nil(t, principal)
+			assert.True(t, errors.Is(err, test.expectedErr), "expected %q, got %q", test.expectedErr, err)
+		})
+	}
+
+	_, err = authenticate("not-a-jwt")
+	assert.Equal(t, ErrNotRecognized, err)
+}
+
+func mustJSON(t *testing.T, value interface{}) []byte {
+	content, err := json.Marshal(value)
+	require.NoError(t, err)
+	return content
+}
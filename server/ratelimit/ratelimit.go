@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiter rate limits the calls of many clients with a token bucket per client, each
+// bucket being refilled at rate tokens per second up to burst tokens. Calls reserve
+// their expected cost in tokens, e.g. the rows they may return, and settle the cost
+// actually spent once served: the unused part is given back as a credit spent by the
+// next calls, a cost above the reservation is charged in chunks of at most burst
+// tokens, the client waiting for each one to be refilled.
+type Limiter struct {
+	rate  rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket is the budget of a client, the tokens of limiter plus the credit given back
+// by the calls that spent less than they reserved. rate.Limiter has no way to give
+// tokens back once a reservation acted, the credit is tracked apart.
+type bucket struct {
+	limiter *rate.Limiter
+	credit  int
+}
+
+// New returns a Limiter granting rate tokens per second to each client, a client being
+// able to spend up to burst tokens at once.
+func New(tokensPerSecond float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate.Limit(tokensPerSecond),
+		burst:   burst,
+		now:     time.Now,
+		clients: make(map[string]*bucket),
+	}
+}
+
+// Reservation is the cost reserved by a call, settled once it is served.
+type Reservation struct {
+	limiter *Limiter
+	client  string
+	tokens  int
+}
+
+// Reserve takes cost tokens from the budget of client when it holds them, returning
+// false and how long until it does otherwise. A cost above the burst is capped to it,
+// the call requiring a full budget, the rest being charged by Settle.
+func (l *Limiter) Reserve(client string, cost int) (reservation *Reservation, retryAfter time.Duration, allowed bool) {
+	cost = min(cost, l.burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.bucket(client, now)
+	fromCredit := min(cost, b.credit)
+	if fromLimiter := cost - fromCredit; fromLimiter > 0 {
+		r := b.limiter.ReserveN(now, fromLimiter)
+		if !r.OK() {
+			return nil, rate.InfDuration, false
+		}
+		if delay := r.DelayFrom(now); delay > 0 {
+			// the reservation has not acted yet, its tokens are all given back
+			r.CancelAt(now)
+			return nil, delay, false
+		}
+	}
+	b.credit -= fromCredit
+	return &Reservation{limiter: l, client: client, tokens: cost}, 0, true
+}
+
+// Settle charges the client the tokens used by the call. Reserved tokens beyond used
+// are credited back, the budget never going above the burst, while tokens used beyond
+// the reservation are taken in chunks of at most burst tokens, delaying the next calls
+// of the client until they are refilled.
+func (r *Reservation) Settle(used int) {
+	l := r.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.bucket(r.client, now)
+	if unused := r.tokens - used; unused > 0 {
+		// tokens are below 0 while the client is charged for a call above its reservation
+		tokens := max(int(b.limiter.TokensAt(now)), 0)
+		b.credit = min(b.credit+unused, l.burst-tokens)
+		return
+	}
+
+	for extra := used - r.tokens; extra > 0; extra -= l.burst {
+		b.limiter.ReserveN(now, min(extra, l.burst))
+	}
+}
+
+// bucket returns the budget of client, l.mu being held.
+func (l *Limiter) bucket(client string, now time.Time) *bucket {
+	l.sweep(now)
+
+	b, found := l.clients[client]
+	if !found {
+		b = &bucket{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.clients[client] = b
+	}
+	return b
+}
+
+// sweep drops the buckets of the clients idle for long enough to be full again, a new
+// bucket being identical, at most once per refill period.
+func (l *Limiter) sweep(now time.Time) {
+	refill := time.Duration(float64(l.burst) / float64(l.rate) * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.clients {
+		if b.limiter.TokensAt(now) >= float64(l.burst) {
+			delete(l.clients, client)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := New(10, 20)
+	limiter.now = func() time.Time { return now }
+
+	_, _, allowed := limiter.Reserve("a", 15)
+	assert.True(t, allowed)
+
+	_, retryAfter, allowed := limiter.Reserve("a", 10)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// clients have their own bucket
+	_, _, allowed = limiter.Reserve("b", 20)
+	assert.True(t, allowed)
+
+	// a denied reservation takes nothing
+	now = now.Add(500 * time.Millisecond)
+	_, _, allowed = limiter.Reserve("a", 10)
+	assert.True(t, allowed)
+
+	// a cost above the burst is capped to it, requiring a full bucket
+	now = now.Add(time.Second)
+	_, retryAfter, allowed = limiter.Reserve("a", 50)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+	now = now.Add(time.Second)
+	_, _, allowed = limiter.Reserve("a", 50)
+	assert.True(t, allowed)
+	_, retryAfter, allowed = limiter.Reserve("a", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+}
+
+func TestLimiter_Settle(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := New(10, 20)
+	limiter.now = func() time.Time { return now }
+
+	reservation, _, allowed := limiter.Reserve("a", 20)
+	require.True(t, allowed)
+	_, _, allowed = limiter.Reserve("a", 1)
+	assert.False(t, allowed)
+
+	// the tokens reserved but not used are credited back
+	reservation.Settle(5)
+	assert.Equal(t, 15, limiter.clients["a"].credit)
+	_, _, allowed = limiter.Reserve("a", 15)
+	assert.True(t, allowed)
+	assert.Equal(t, 0, limiter.clients["a"].credit)
+	_, retryAfter, allowed := limiter.Reserve("a", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+
+	// using the whole reservation gives nothing back
+	reservation, _, allowed = limiter.Reserve("b", 10)
+	require.True(t, allowed)
+	reservation.Settle(10)
+	assert.Equal(t, 0, limiter.clients["b"].credit)
+	assert.Equal(t, 10.0, limiter.clients["b"].limiter.TokensAt(now))
+
+	// using more than the reservation is charged, even beyond the burst
+	reservation, _, allowed = limiter.Reserve("d", 10)
+	require.True(t, allowed)
+	reservation.Settle(45)
+	assert.Equal(t, -25.0, limiter.clients["d"].limiter.TokensAt(now))
+	_, retryAfter, allowed = limiter.Reserve("d", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, 2600*time.Millisecond, retryAfter)
+
+	// the budget refilled while the call was served never goes above the burst
+	reservation, _, allowed = limiter.Reserve("c", 10)
+	require.True(t, allowed)
+	now = now.Add(500 * time.Millisecond)
+	reservation.Settle(1)
+	assert.Equal(t, 5, limiter.clients["c"].credit)
+	_, retryAfter, _ = limiter.Reserve("c", 20)
+	assert.Equal(t, time.Duration(0), retryAfter)
+	_, retryAfter, _ = limiter.Reserve("c", 1)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := New(10, 20)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Reserve("a", 20)
+	limiter.Reserve("b", 5)
+	assert.Len(t, limiter.clients, 2)
+
+	now = now.Add(2 * time.Second)
+	limiter.Reserve("c", 1)
+	assert.Len(t, limiter.clients, 1)
+	assert.Contains(t, limiter.clients, "c")
+}
@@ -5,7 +5,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/streamingfast/dgrpc/server"
 	connectweb "github.com/streamingfast/dgrpc/server/connect-web"
@@ -13,22 +18,38 @@ import (
 	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
 	kvconnect "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1/kvv1connect"
 	sserver "github.com/streamingfast/substreams-sink-kv/server"
+	"github.com/streamingfast/substreams-sink-kv/server/auth"
+	"github.com/streamingfast/substreams-sink-kv/server/ratelimit"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 var _ sserver.Serveable = (*ConnectServer)(nil)
 
-func NewServer(dbReader db.Reader, logger *zap.Logger, encrypted bool) *ConnectServer {
+// NewServer creates the Kv service server, watcher is nil when the server does not
+// run along the injector, `Watch` is then unimplemented. valueTypes is nil when the
+// sink config declares no value types, values are then always returned as bytes.
+func NewServer(dbReader db.Reader, watcher db.Watcher, valueTypes *ValueTypes, logger *zap.Logger, encrypted bool, opts ...Option) *ConnectServer {
 	cs := &ConnectServer{
-		DBReader: dbReader,
-		logger:   logger,
+		DBReader:        dbReader,
+		watcher:         watcher,
+		valueTypes:      valueTypes,
+		logger:          logger,
+		minBlockTimeout: DefaultMinBlockTimeout,
+	}
+	for _, opt := range opts {
+		opt.apply(cs)
 	}
 
 	handlerGetter := func(opts ...connect.HandlerOption) (string, http.Handler) {
-		return kvconnect.NewKvHandler(cs)
+		if cs.authenticator != nil {
+			opts = append(opts, connect.WithInterceptors(auth.NewInterceptor(cs.authenticator)))
+		}
+		return kvconnect.NewKvHandler(cs, opts...)
 	}
 
-	opts := []server.Option{
+	serverOpts := []server.Option{
 		server.WithReflection("sf.substreams.sink.kv.v1.Kv"),
 		server.WithLogger(logger),
 		server.WithPermissiveCORS(),
@@ -36,19 +57,35 @@ func NewServer(dbReader db.Reader, logger *zap.Logger, encrypted bool) *ConnectS
 	}
 
 	if encrypted {
-		opts = append(opts, server.WithInsecureServer())
+		serverOpts = append(serverOpts, server.WithInsecureServer())
 	} else {
-		opts = append(opts, server.WithPlainTextServer())
+		serverOpts = append(serverOpts, server.WithPlainTextServer())
 	}
-	cs.srv = connectweb.New([]connectweb.HandlerGetter{handlerGetter}, opts...)
+	cs.srv = connectweb.New([]connectweb.HandlerGetter{handlerGetter}, serverOpts...)
 	return cs
 }
 
 type ConnectServer struct {
 	kvconnect.UnimplementedKvHandler
-	srv      *connectweb.ConnectWebServer
-	DBReader db.Reader
-	logger   *zap.Logger
+	srv        *connectweb.ConnectWebServer
+	DBReader   db.Reader
+	watcher    db.Watcher
+	valueTypes *ValueTypes
+	logger     *zap.Logger
+
+	// minBlockTimeout bounds the wait of the reads with `min_block`, see committedBlock
+	minBlockTimeout time.Duration
+
+	// authenticator is nil when the requests are not authenticated, see authorize
+	authenticator auth.Authenticator
+
+	// limiter is nil when the requests are not rate limited, see rateLimit
+	limiter *ratelimit.Limiter
+
+	// queryRowsLimit and queryKeysLimit are the limits of the reads without one, 0 when
+	// unknown, see expectedRows
+	queryRowsLimit int
+	queryKeysLimit int
 }
 
 func (cs *ConnectServer) Shutdown() {
@@ -65,29 +102,63 @@ func (cs *ConnectServer) Serve(listenAddr string) error {
 
 func (cs *ConnectServer) Get(ctx context.Context, req *connect.Request[kvv1.GetRequest]) (*connect.Response[kvv1.GetResponse], error) {
 	logger := cs.logger.With(zap.String("key", req.Msg.Key))
-	val, err := cs.DBReader.Get(ctx, req.Msg.Key)
+	if err := cs.authorize(ctx, logger, func(p *auth.Principal) bool { return p.CanRead(req.Msg.Key) }); err != nil {
+		return nil, err
+	}
+	if _, err := cs.rateLimit(ctx, req.Peer(), "Get", 1, logger); err != nil {
+		return nil, err
+	}
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+	val, err := cs.DBReader.Get(ctx, req.Msg.Key, readOptions(req.Msg.AtBlock, req.Msg.Consistency, "", false, false)...)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			logger.Debug("key not found", zap.Error(err))
 			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("requested key not found in database: %w", err))
 		}
+		if errors.Is(err, db.ErrInvalidArguments) {
+			logger.Debug("invalid arguments", zap.Error(err))
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
 		logger.Info("internal error", zap.Error(err))
 		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
 	}
-	resp := connect.NewResponse(&kvv1.GetResponse{
+	msg := &kvv1.GetResponse{
 		Value: val,
-	})
-	return resp, nil
+		Block: block,
+	}
+	if req.Msg.Format == kvv1.Format_JSON {
+		if jsonValue, ok := cs.valueTypes.toJSON(req.Msg.Key, val, logger); ok {
+			msg.Value, msg.JsonValue = nil, jsonValue
+		}
+	}
+	return withBlockHeaders(connect.NewResponse(msg), block), nil
 }
 
 func (cs *ConnectServer) GetMany(ctx context.Context, req *connect.Request[kvv1.GetManyRequest]) (*connect.Response[kvv1.GetManyResponse], error) {
 	logger := cs.logger.With(zap.Strings("keys", req.Msg.Keys))
-	vals, err := cs.DBReader.GetMany(ctx, req.Msg.Keys)
-	if err != nil {
-		if errors.Is(err, db.ErrNotFound) {
-			logger.Debug("key not found", zap.Error(err))
-			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("one of the requested keys was not found in database: %w", err))
+	err := cs.authorize(ctx, logger, func(p *auth.Principal) bool {
+		for _, key := range req.Msg.Keys {
+			if !p.CanRead(key) {
+				return false
+			}
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cs.rateLimit(ctx, req.Peer(), "GetMany", len(req.Msg.Keys), logger); err != nil {
+		return nil, err
+	}
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := cs.DBReader.GetMany(ctx, req.Msg.Keys)
+	if err != nil {
 		if errors.Is(err, db.ErrInvalidArguments) {
 			logger.Debug("invalid arguments", zap.Error(err))
 			return nil, connect.NewError(connect.CodeInvalidArgument, err)
@@ -95,15 +166,49 @@ func (cs *ConnectServer) GetMany(ctx context.Context, req *connect.Request[kvv1.
 		logger.Info("internal error", zap.Error(err))
 		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
 	}
-	resp := connect.NewResponse(&kvv1.GetManyResponse{
-		Values: vals,
-	})
-	return resp, nil
+	if req.Msg.Format == kvv1.Format_JSON {
+		for _, entry := range entries {
+			if !entry.Found {
+				continue
+			}
+			if jsonValue, ok := cs.valueTypes.toJSON(entry.Key, entry.Value, logger); ok {
+				entry.Value, entry.JsonValue = nil, jsonValue
+			}
+		}
+	}
+
+	msg := &kvv1.GetManyResponse{Entries: entries, Block: block}
+	// the deprecated values are still returned, for the clients predating entries
+	msg.Values = make([][]byte, len(entries))
+	if req.Msg.Format == kvv1.Format_JSON {
+		msg.JsonValues = make([]string, len(entries))
+	}
+	for i, entry := range entries {
+		msg.Values[i] = entry.Value
+		if msg.JsonValues != nil {
+			msg.JsonValues[i] = entry.JsonValue
+		}
+	}
+	return withBlockHeaders(connect.NewResponse(msg), block), nil
 }
 
 func (cs *ConnectServer) GetByPrefix(ctx context.Context, req *connect.Request[kvv1.GetByPrefixRequest]) (*connect.Response[kvv1.GetByPrefixResponse], error) {
 	logger := cs.logger.With(zap.String("prefix", req.Msg.Prefix), zap.Uint64("limit", req.Msg.Limit))
-	keyVals, limitReached, err := cs.DBReader.GetByPrefix(ctx, req.Msg.Prefix, int(req.Msg.Limit))
+	if err := cs.authorize(ctx, logger, func(p *auth.Principal) bool { return p.CanReadPrefix(req.Msg.Prefix) }); err != nil {
+		return nil, err
+	}
+	reservation, err := cs.rateLimit(ctx, req.Peer(), "GetByPrefix", cs.expectedRows(req.Msg.Limit, req.Msg.KeysOnly), logger)
+	if err != nil {
+		return nil, err
+	}
+	served := 0
+	defer func() { cs.settle(reservation, served) }()
+
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+	keyVals, limitReached, err := cs.DBReader.GetByPrefix(ctx, req.Msg.Prefix, int(req.Msg.Limit), readOptions(req.Msg.AtBlock, req.Msg.Consistency, req.Msg.PageToken, req.Msg.Reverse, req.Msg.KeysOnly)...)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			logger.Debug("prefix not found", zap.Error(err))
@@ -116,6 +221,8 @@ func (cs *ConnectServer) GetByPrefix(ctx context.Context, req *connect.Request[k
 		logger.Info("internal error", zap.Error(err))
 		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
 	}
+	served = len(keyVals)
+
 	protoKeyVals := make([]*kvv1.KV, len(keyVals))
 	for i := range keyVals {
 		protoKeyVals[i] = &kvv1.KV{
@@ -123,11 +230,16 @@ func (cs *ConnectServer) GetByPrefix(ctx context.Context, req *connect.Request[k
 			Value: keyVals[i].Value,
 		}
 	}
+	if req.Msg.Format == kvv1.Format_JSON {
+		cs.formatJSON(protoKeyVals, logger)
+	}
 	resp := connect.NewResponse(&kvv1.GetByPrefixResponse{
-		KeyValues:    protoKeyVals,
-		LimitReached: limitReached,
+		KeyValues:     protoKeyVals,
+		LimitReached:  limitReached,
+		NextPageToken: db.NextPrefixPageToken(req.Msg.Prefix, req.Msg.Reverse, keyVals, limitReached),
+		Block:         block,
 	})
-	return resp, nil
+	return withBlockHeaders(resp, block), nil
 }
 
 func (cs *ConnectServer) Scan(ctx context.Context, req *connect.Request[kvv1.ScanRequest]) (*connect.Response[kvv1.ScanResponse], error) {
@@ -136,7 +248,21 @@ func (cs *ConnectServer) Scan(ctx context.Context, req *connect.Request[kvv1.Sca
 	if req.Msg.ExclusiveEnd != nil {
 		exclusiveEnd = *req.Msg.ExclusiveEnd
 	}
-	keyVals, limitReached, err := cs.DBReader.Scan(ctx, req.Msg.Begin, exclusiveEnd, int(req.Msg.Limit))
+	if err := cs.authorize(ctx, logger, func(p *auth.Principal) bool { return p.CanReadRange(req.Msg.Begin, exclusiveEnd) }); err != nil {
+		return nil, err
+	}
+	reservation, err := cs.rateLimit(ctx, req.Peer(), "Scan", cs.expectedRows(req.Msg.Limit, req.Msg.KeysOnly), logger)
+	if err != nil {
+		return nil, err
+	}
+	served := 0
+	defer func() { cs.settle(reservation, served) }()
+
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+	keyVals, limitReached, err := cs.DBReader.Scan(ctx, req.Msg.Begin, exclusiveEnd, int(req.Msg.Limit), readOptions(req.Msg.AtBlock, req.Msg.Consistency, req.Msg.PageToken, req.Msg.Reverse, req.Msg.KeysOnly)...)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			logger.Debug("no values found", zap.Error(err))
@@ -149,6 +275,8 @@ func (cs *ConnectServer) Scan(ctx context.Context, req *connect.Request[kvv1.Sca
 		logger.Info("internal error", zap.Error(err))
 		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
 	}
+	served = len(keyVals)
+
 	protoKeyVals := make([]*kvv1.KV, len(keyVals))
 	for i := range keyVals {
 		protoKeyVals[i] = &kvv1.KV{
@@ -156,9 +284,428 @@ func (cs *ConnectServer) Scan(ctx context.Context, req *connect.Request[kvv1.Sca
 			Value: keyVals[i].Value,
 		}
 	}
+	if req.Msg.Format == kvv1.Format_JSON {
+		cs.formatJSON(protoKeyVals, logger)
+	}
 	resp := connect.NewResponse(&kvv1.ScanResponse{
-		KeyValues:    protoKeyVals,
+		KeyValues:     protoKeyVals,
+		LimitReached:  limitReached,
+		NextPageToken: db.NextScanPageToken(req.Msg.Begin, exclusiveEnd, req.Msg.Reverse, keyVals, limitReached),
+		Block:         block,
+	})
+	return withBlockHeaders(resp, block), nil
+}
+
+func (cs *ConnectServer) ReadChanges(ctx context.Context, req *connect.Request[kvv1.ReadChangesRequest]) (*connect.Response[kvv1.ReadChangesResponse], error) {
+	logger := cs.logger.With(zap.Uint64("from_sequence", req.Msg.FromSequence), zap.Uint64("limit", req.Msg.Limit))
+	if err := cs.authorize(ctx, logger, (*auth.Principal).CanReadAll); err != nil {
+		return nil, err
+	}
+	reservation, err := cs.rateLimit(ctx, req.Peer(), "ReadChanges", cs.expectedRows(req.Msg.Limit, false), logger)
+	if err != nil {
+		return nil, err
+	}
+	served := 0
+	defer func() { cs.settle(reservation, served) }()
+
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+	entries, nextSequence, limitReached, err := cs.DBReader.ReadChanges(ctx, req.Msg.FromSequence, int(req.Msg.Limit))
+	if err != nil {
+		if errors.Is(err, db.ErrChangesPurged) {
+			logger.Debug("changes purged", zap.Error(err))
+			return nil, connect.NewError(connect.CodeOutOfRange, err)
+		}
+		if errors.Is(err, db.ErrInvalidArguments) {
+			logger.Debug("invalid arguments", zap.Error(err))
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		logger.Info("internal error", zap.Error(err))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+	}
+	served = len(entries)
+
+	resp := connect.NewResponse(&kvv1.ReadChangesResponse{
+		Entries:      entries,
+		NextSequence: nextSequence,
 		LimitReached: limitReached,
+		Block:        block,
 	})
-	return resp, nil
+	return withBlockHeaders(resp, block), nil
+}
+
+func (cs *ConnectServer) QueryIndex(ctx context.Context, req *connect.Request[kvv1.QueryIndexRequest]) (*connect.Response[kvv1.QueryIndexResponse], error) {
+	logger := cs.logger.With(zap.String("index", req.Msg.Index), zap.String("value", req.Msg.Value), zap.Uint64("limit", req.Msg.Limit))
+	// the keys of an index value can live under any prefix
+	if err := cs.authorize(ctx, logger, (*auth.Principal).CanReadAll); err != nil {
+		return nil, err
+	}
+	reservation, err := cs.rateLimit(ctx, req.Peer(), "QueryIndex", cs.expectedRows(req.Msg.Limit, false), logger)
+	if err != nil {
+		return nil, err
+	}
+	served := 0
+	defer func() { cs.settle(reservation, served) }()
+
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+	keys, limitReached, err := cs.DBReader.QueryIndex(ctx, req.Msg.Index, req.Msg.Value, int(req.Msg.Limit))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			logger.Debug("no keys found", zap.Error(err))
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("no keys found for the requested index value: %w", err))
+		}
+		if errors.Is(err, db.ErrInvalidArguments) {
+			logger.Debug("invalid arguments", zap.Error(err))
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		logger.Info("internal error", zap.Error(err))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+	}
+	served = len(keys)
+
+	resp := connect.NewResponse(&kvv1.QueryIndexResponse{
+		Keys:         keys,
+		LimitReached: limitReached,
+		Block:        block,
+	})
+	return withBlockHeaders(resp, block), nil
+}
+
+func (cs *ConnectServer) DescribeValueTypes(ctx context.Context, req *connect.Request[kvv1.DescribeValueTypesRequest]) (*connect.Response[kvv1.DescribeValueTypesResponse], error) {
+	if err := cs.authorize(ctx, cs.logger, func(*auth.Principal) bool { return true }); err != nil {
+		return nil, err
+	}
+	if _, err := cs.rateLimit(ctx, req.Peer(), "DescribeValueTypes", 1, cs.logger); err != nil {
+		return nil, err
+	}
+	msg := &kvv1.DescribeValueTypesResponse{}
+	if cs.valueTypes != nil {
+		msg.ValueTypes = cs.valueTypes.configs
+		msg.FileDescriptorSet = cs.valueTypes.fileDescriptorSet
+	}
+	return connect.NewResponse(msg), nil
+}
+
+func (cs *ConnectServer) Count(ctx context.Context, req *connect.Request[kvv1.CountRequest]) (*connect.Response[kvv1.CountResponse], error) {
+	logger := cs.logger.With(zap.String("prefix", req.Msg.Prefix), zap.String("begin", req.Msg.Begin), zap.Stringp("exclusive_end", req.Msg.ExclusiveEnd))
+	err := cs.authorize(ctx, logger, func(p *auth.Principal) bool {
+		if req.Msg.Prefix != "" {
+			return p.CanReadPrefix(req.Msg.Prefix)
+		}
+		return p.CanReadRange(req.Msg.Begin, req.Msg.GetExclusiveEnd())
+	})
+	if err != nil {
+		return nil, err
+	}
+	// counts scan up to the query keys limit, they are charged by keys scanned
+	reservation, err := cs.rateLimit(ctx, req.Peer(), "Count", cs.expectedRows(0, true), logger)
+	if err != nil {
+		return nil, err
+	}
+	scanned := 0
+	defer func() { cs.settle(reservation, scanned) }()
+
+	block, err := cs.committedBlock(ctx, req.Msg.MinBlock, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var count uint64
+	var limitReached bool
+	if req.Msg.Prefix != "" {
+		count, limitReached, err = cs.DBReader.CountPrefix(ctx, req.Msg.Prefix)
+	} else {
+		count, limitReached, err = cs.DBReader.Count(ctx, req.Msg.Begin, req.Msg.GetExclusiveEnd())
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidArguments) {
+			logger.Debug("invalid arguments", zap.Error(err))
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		logger.Info("internal error", zap.Error(err))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+	}
+	scanned = int(min(count, math.MaxInt32))
+	resp := connect.NewResponse(&kvv1.CountResponse{
+		Count:        count,
+		LimitReached: limitReached,
+		Block:        block,
+	})
+	return withBlockHeaders(resp, block), nil
+}
+
+// authorize checks that the principal of ctx is allowed the read, always passing when
+// the server has no authenticator.
+func (cs *ConnectServer) authorize(ctx context.Context, logger *zap.Logger, allowed func(principal *auth.Principal) bool) error {
+	if cs.authenticator == nil {
+		return nil
+	}
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("request is not authenticated"))
+	}
+	if !allowed(principal) {
+		logger.Debug("permission denied", zap.String("principal", principal.Name), zap.Strings("prefixes", principal.Prefixes))
+		return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("%q is not allowed to read the requested keys", principal.Name))
+	}
+	return nil
+}
+
+// rateLimit reserves cost from the budget of the client of the request, failing with
+// CodeResourceExhausted and a retry delay when it is spent. The reservation is nil when
+// the requests are not rate limited, see settle for the costs only known once served.
+func (cs *ConnectServer) rateLimit(ctx context.Context, peer connect.Peer, rpc string, cost int, logger *zap.Logger) (*ratelimit.Reservation, error) {
+	if cs.limiter == nil {
+		return nil, nil
+	}
+
+	client := rateLimitClient(ctx, peer)
+	reservation, retryAfter, allowed := cs.limiter.Reserve(client, cost)
+	if allowed {
+		return reservation, nil
+	}
+
+	ThrottledRequests.Inc(rpc)
+	logger.Debug("rate limited", zap.String("client", client), zap.Int("cost", cost), zap.Duration("retry_after", retryAfter))
+	connectErr := connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("rate limit exceeded, retry in %s", retryAfter.Round(time.Millisecond)))
+	connectErr.Meta().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+	if detail, err := connect.NewErrorDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return nil, connectErr
+}
+
+// expectedRows is the cost reserved by a read of up to limit rows, 0 meaning the query
+// rows limit or, for keys only reads, the query keys limit. Unknown limits reserve the
+// whole budget of the client.
+func (cs *ConnectServer) expectedRows(limit uint64, keysOnly bool) int {
+	if limit == 0 {
+		limit = uint64(cs.queryRowsLimit)
+		if keysOnly {
+			limit = uint64(cs.queryKeysLimit)
+		}
+	}
+	if limit == 0 || limit > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(limit)
+}
+
+// settle charges the rows served by the request in place of the cost reserved by
+// rateLimit, a request costing at least one token.
+func (cs *ConnectServer) settle(reservation *ratelimit.Reservation, rows int) {
+	if reservation != nil {
+		reservation.Settle(max(rows, 1))
+	}
+}
+
+type remoteAddrKey struct{}
+
+// rateLimitClient identifies the client of a request, by its principal when it is
+// authenticated and by its remote IP otherwise.
+func rateLimitClient(ctx context.Context, peer connect.Peer) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		return "principal:" + principal.Name
+	}
+
+	// the requests of the REST gateway carry the remote address in their context
+	addr := peer.Addr
+	if addr == "" {
+		addr, _ = ctx.Value(remoteAddrKey{}).(string)
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "ip:" + addr
+}
+
+// Headers of the read responses carrying their CommittedBlock, absent when no block was
+// committed yet.
+const (
+	BlockNumberHeader      = "X-Block-Number"
+	BlockIDHeader          = "X-Block-Id"
+	FinalBlockHeightHeader = "X-Final-Block-Height"
+)
+
+// minBlockPollInterval is how often a server running apart from the injector reads the
+// committed block while waiting for `min_block`.
+const minBlockPollInterval = 250 * time.Millisecond
+
+// committedBlock returns the block committed by the injector, read before the data so
+// that the data reflects at least this block. With minBlock, it first waits up to
+// minBlockTimeout for minBlock to be committed, failing with CodeFailedPrecondition.
+func (cs *ConnectServer) committedBlock(ctx context.Context, minBlock *uint64, logger *zap.Logger) (*kvv1.CommittedBlock, error) {
+	if minBlock == nil {
+		block, err := cs.DBReader.CommittedBlock(ctx)
+		if err != nil {
+			logger.Info("internal error", zap.Error(err))
+			return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+		}
+		return block, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, cs.minBlockTimeout)
+	defer cancel()
+
+	block, err := cs.waitForBlock(waitCtx, *minBlock)
+	if err == nil {
+		return block, nil
+	}
+	if ctx.Err() != nil {
+		return nil, connect.NewError(contextErrorCode(ctx.Err()), ctx.Err())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Debug("min block not reached", zap.Uint64("min_block", *minBlock), zap.Uint64("block", block.GetBlockNumber()))
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("request value for 'min_block' %d was not reached within %s, last committed block is %d", *minBlock, cs.minBlockTimeout, block.GetBlockNumber()))
+	}
+	logger.Info("internal error", zap.Error(err))
+	return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+}
+
+// waitForBlock waits on the flushes when the server runs along the injector, polling
+// the store otherwise.
+func (cs *ConnectServer) waitForBlock(ctx context.Context, blockNum uint64) (*kvv1.CommittedBlock, error) {
+	if cs.watcher != nil {
+		return cs.watcher.WaitForBlock(ctx, blockNum)
+	}
+
+	ticker := time.NewTicker(minBlockPollInterval)
+	defer ticker.Stop()
+	for {
+		block, err := cs.DBReader.CommittedBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil && block.BlockNumber >= blockNum {
+			return block, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return block, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func contextErrorCode(err error) connect.Code {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return connect.CodeDeadlineExceeded
+	}
+	return connect.CodeCanceled
+}
+
+func withBlockHeaders[T any](resp *connect.Response[T], block *kvv1.CommittedBlock) *connect.Response[T] {
+	if block != nil {
+		resp.Header().Set(BlockNumberHeader, strconv.FormatUint(block.BlockNumber, 10))
+		resp.Header().Set(BlockIDHeader, block.BlockId)
+		resp.Header().Set(FinalBlockHeightHeader, strconv.FormatUint(block.FinalBlockHeight, 10))
+	}
+	return resp
+}
+
+// formatJSON replaces the values of keyVals by their JSON form when their type is known.
+func (cs *ConnectServer) formatJSON(keyVals []*kvv1.KV, logger *zap.Logger) {
+	for _, kv := range keyVals {
+		if jsonValue, ok := cs.valueTypes.toJSON(kv.Key, kv.Value, logger); ok {
+			kv.Value, kv.JsonValue = nil, jsonValue
+		}
+	}
+}
+
+// watchBufferSize is the number of flushes a Watch stream can lag behind before being
+// closed.
+const watchBufferSize = 100
+
+func (cs *ConnectServer) Watch(ctx context.Context, req *connect.Request[kvv1.WatchRequest], stream *connect.ServerStream[kvv1.WatchResponse]) error {
+	if cs.watcher == nil {
+		return connect.NewError(connect.CodeUnimplemented, errors.New("watching requires the server to run along the injector, see 'inject --server-listen-addr'"))
+	}
+
+	logger := cs.logger.With(zap.Stringp("key", req.Msg.Key), zap.String("prefix", req.Msg.Prefix))
+	err := cs.authorize(ctx, logger, func(p *auth.Principal) bool {
+		if req.Msg.Key != nil {
+			return p.CanRead(*req.Msg.Key)
+		}
+		return p.CanReadPrefix(req.Msg.Prefix)
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := cs.rateLimit(ctx, req.Peer(), "Watch", 1, logger); err != nil {
+		return err
+	}
+	logger.Debug("watch started")
+
+	subscription := cs.watcher.Subscribe(watchBufferSize)
+	defer subscription.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debug("watch ended")
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				logger.Debug("watch subscription closed", zap.Error(subscription.Err()))
+				return connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("watch closed: %w", subscription.Err()))
+			}
+
+			resp := watchResponse(req.Msg, event)
+			if len(resp.Changes) == 0 {
+				continue
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func watchResponse(req *kvv1.WatchRequest, event *db.FlushEvent) *kvv1.WatchResponse {
+	resp := &kvv1.WatchResponse{Undo: event.Undo}
+	if event.Cursor != nil {
+		resp.BlockNumber = event.Cursor.Block().Num()
+		resp.Cursor = event.Cursor.String()
+	}
+
+	for _, op := range event.Changes {
+		if req.Key != nil && op.Key != *req.Key {
+			continue
+		}
+		if req.Key == nil && !strings.HasPrefix(op.Key, req.Prefix) {
+			continue
+		}
+
+		change := &kvv1.Change{Type: kvv1.Change_SET, Key: op.Key, Value: op.Value}
+		if op.Type == kvv1.KVOperation_DELETE {
+			change = &kvv1.Change{Type: kvv1.Change_DELETE, Key: op.Key}
+		}
+		resp.Changes = append(resp.Changes, change)
+	}
+	return resp
+}
+
+func readOptions(atBlock *uint64, consistency kvv1.Consistency, pageToken string, reverse bool, keysOnly bool) (opts []db.ReadOption) {
+	if atBlock != nil {
+		opts = append(opts, db.AtBlock(*atBlock))
+	}
+	if consistency == kvv1.Consistency_FINAL {
+		opts = append(opts, db.Final())
+	}
+	if pageToken != "" {
+		opts = append(opts, db.PageToken(pageToken))
+	}
+	if reverse {
+		opts = append(opts, db.Reverse())
+	}
+	if keysOnly {
+		opts = append(opts, db.KeysOnly())
+	}
+	return opts
 }
@@ -0,0 +1,11 @@
+package standard
+
+import "github.com/streamingfast/dmetrics"
+
+func RegisterMetrics() {
+	metrics.Register()
+}
+
+var metrics = dmetrics.NewSet()
+
+var ThrottledRequests = metrics.NewCounterVec("substreams_sink_kv_server_throttled_requests", []string{"rpc"}, "The number of requests rejected by the rate limiter, per RPC")
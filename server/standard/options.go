@@ -0,0 +1,69 @@
+package standard
+
+import (
+	"time"
+
+	"github.com/streamingfast/substreams-sink-kv/server/auth"
+	"github.com/streamingfast/substreams-sink-kv/server/ratelimit"
+)
+
+type Option interface {
+	apply(cs *ConnectServer)
+}
+
+// DefaultMinBlockTimeout is how long a read with `min_block` waits by default for the
+// block to be committed.
+const DefaultMinBlockTimeout = 10 * time.Second
+
+type minBlockTimeoutOpt time.Duration
+
+// WithMinBlockTimeout sets how long a read with `min_block` waits for the block to be
+// committed before failing, DefaultMinBlockTimeout otherwise.
+func WithMinBlockTimeout(timeout time.Duration) Option {
+	return minBlockTimeoutOpt(timeout)
+}
+
+func (o minBlockTimeoutOpt) apply(cs *ConnectServer) {
+	cs.minBlockTimeout = time.Duration(o)
+}
+
+type authenticatorOpt struct{ authenticator auth.Authenticator }
+
+// WithAuthenticator requires the requests to be authenticated by authenticator, each
+// read being then restricted to the key prefixes of its auth.Principal.
+func WithAuthenticator(authenticator auth.Authenticator) Option {
+	return authenticatorOpt{authenticator: authenticator}
+}
+
+func (o authenticatorOpt) apply(cs *ConnectServer) {
+	cs.authenticator = o.authenticator
+}
+
+type rateLimiterOpt struct{ limiter *ratelimit.Limiter }
+
+// WithRateLimiter charges each request to the budget of its client in limiter, one token
+// per request plus one per row or key returned beyond the first, Count being charged one
+// per key counted and GetMany one per requested key. The rows of a read are reserved up
+// to its limit before it is served, see WithQueryLimits, the rows served beyond the
+// reservation being charged afterwards.
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return rateLimiterOpt{limiter: limiter}
+}
+
+func (o rateLimiterOpt) apply(cs *ConnectServer) {
+	cs.limiter = o.limiter
+}
+
+type queryLimitsOpt struct{ rowsLimit, keysLimit int }
+
+// WithQueryLimits sets the limits of the reads without one, the query rows limit and the
+// query keys limit of the keys only reads, reserved by the rate limiter before serving
+// them. The whole budget of the client is reserved otherwise.
+func WithQueryLimits(rowsLimit, keysLimit int) Option {
+	return queryLimitsOpt{rowsLimit: rowsLimit, keysLimit: keysLimit}
+}
+
+func (o queryLimitsOpt) apply(cs *ConnectServer) {
+	cs.queryRowsLimit = o.rowsLimit
+	cs.queryKeysLimit = o.keysLimit
+}
@@ -0,0 +1,418 @@
+package standard
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"connectrpc.com/connect"
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	sserver "github.com/streamingfast/substreams-sink-kv/server"
+	"github.com/streamingfast/substreams-sink-kv/server/auth"
+	"go.uber.org/zap"
+)
+
+var _ sserver.Serveable = (*RESTGateway)(nil)
+
+// ValueEncoding is how the REST gateway writes values in its JSON responses.
+type ValueEncoding string
+
+const (
+	ValueEncodingBase64 ValueEncoding = "base64"
+	ValueEncodingHex    ValueEncoding = "hex"
+	ValueEncodingRaw    ValueEncoding = "raw"
+)
+
+func ParseValueEncoding(in string) (ValueEncoding, error) {
+	switch encoding := ValueEncoding(strings.ToLower(in)); encoding {
+	case ValueEncodingBase64, ValueEncodingHex, ValueEncodingRaw:
+		return encoding, nil
+	default:
+		return "", fmt.Errorf("invalid value encoding %q, must be one of %q, %q or %q", in, ValueEncodingBase64, ValueEncodingHex, ValueEncodingRaw)
+	}
+}
+
+// encode returns value encoded and the encoding used, raw values that are not valid
+// UTF-8 being base64 encoded since JSON strings would replace the invalid bytes.
+func (e ValueEncoding) encode(value []byte) (string, ValueEncoding) {
+	switch e {
+	case ValueEncodingHex:
+		return hex.EncodeToString(value), e
+	case ValueEncodingRaw:
+		if utf8.Valid(value) {
+			return string(value), e
+		}
+	}
+	return base64.StdEncoding.EncodeToString(value), ValueEncodingBase64
+}
+
+// RESTGateway serves the read RPCs of a ConnectServer as plain HTTP routes:
+//
+//   - `GET /v1/kv/{key}`
+//   - `GET /v1/prefix/{prefix}?limit=&page_token=&reverse=&keys_only=`
+//   - `GET /v1/scan?begin=&end=&limit=&page_token=&reverse=&keys_only=`
+//   - `GET /v1/count?prefix=` or `GET /v1/count?begin=&end=`
+//
+// Every route accepts `min_block`, `at_block`, `consistency=final`, `format=json` and
+// `encoding` (overriding the default value encoding) query parameters. Errors are
+// returned with the HTTP status mirroring their Connect code.
+type RESTGateway struct {
+	cs       *ConnectServer
+	encoding ValueEncoding
+	logger   *zap.Logger
+
+	srv *http.Server
+}
+
+func NewRESTGateway(cs *ConnectServer, encoding ValueEncoding, logger *zap.Logger) *RESTGateway {
+	g := &RESTGateway{
+		cs:       cs,
+		encoding: encoding,
+		logger:   logger,
+	}
+	g.srv = &http.Server{Handler: g, ReadHeaderTimeout: 30 * time.Second}
+	return g
+}
+
+func (g *RESTGateway) Serve(listenAddr string) error {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("listening on %q: %w", listenAddr, err)
+	}
+
+	g.logger.Info("rest gateway listening", zap.String("listen_addr", listener.Addr().String()))
+	if err := g.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (g *RESTGateway) Shutdown() {
+	g.logger.Info("rest gateway received shutdown, shutting down server")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := g.srv.Shutdown(ctx); err != nil {
+		g.logger.Warn("rest gateway did not shut down cleanly", zap.Error(err))
+	}
+}
+
+type restKV struct {
+	Key       string          `json:"key"`
+	Value     *string         `json:"value,omitempty"`
+	JSONValue json.RawMessage `json:"json_value,omitempty"`
+	// Encoding is only set on values not encoded as requested, see ValueEncoding.encode
+	Encoding ValueEncoding `json:"encoding,omitempty"`
+}
+
+type restKVs struct {
+	KeyValues     []*restKV `json:"key_values"`
+	LimitReached  bool      `json:"limit_reached"`
+	NextPageToken string    `json:"next_page_token,omitempty"`
+}
+
+type restCount struct {
+	Count        uint64 `json:"count"`
+	LimitReached bool   `json:"limit_reached"`
+}
+
+type restError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (g *RESTGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		g.writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("method %s is not allowed, only GET is", r.Method))
+		return
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), remoteAddrKey{}, r.RemoteAddr))
+	if g.cs.authenticator != nil {
+		principal, err := g.cs.authenticator.Authenticate(r.Context(), r.Header)
+		if err != nil {
+			g.writeError(w, http.StatusUnauthorized, connect.CodeUnauthenticated.String(), err.Error())
+			return
+		}
+		r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+	case strings.HasPrefix(path, "/v1/kv/"):
+		g.serveGet(w, r, strings.TrimPrefix(path, "/v1/kv/"))
+	case strings.HasPrefix(path, "/v1/prefix/"):
+		g.servePrefix(w, r, strings.TrimPrefix(path, "/v1/prefix/"))
+	case path == "/v1/scan":
+		g.serveScan(w, r)
+	case path == "/v1/count":
+		g.serveCount(w, r)
+	default:
+		g.writeError(w, http.StatusNotFound, connect.CodeNotFound.String(), fmt.Sprintf("no route for %s", r.URL.Path))
+	}
+}
+
+func (g *RESTGateway) serveGet(w http.ResponseWriter, r *http.Request, escapedKey string) {
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		g.writeConnectError(w, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid key: %w", err)))
+		return
+	}
+
+	params, err := g.parseParams(r.URL.Query())
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+
+	resp, err := g.cs.Get(r.Context(), connect.NewRequest(&kvv1.GetRequest{Key: key, AtBlock: params.atBlock, Consistency: params.consistency, Format: params.format, MinBlock: params.minBlock}))
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+	copyBlockHeaders(w.Header(), resp.Header())
+	g.writeJSON(w, http.StatusOK, params.kv(key, resp.Msg.Value, resp.Msg.JsonValue))
+}
+
+func (g *RESTGateway) servePrefix(w http.ResponseWriter, r *http.Request, escapedPrefix string) {
+	prefix, err := url.PathUnescape(escapedPrefix)
+	if err != nil {
+		g.writeConnectError(w, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid prefix: %w", err)))
+		return
+	}
+
+	params, err := g.parseParams(r.URL.Query())
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+
+	resp, err := g.cs.GetByPrefix(r.Context(), connect.NewRequest(&kvv1.GetByPrefixRequest{Prefix: prefix, Limit: params.limit, AtBlock: params.atBlock, Consistency: params.consistency, Format: params.format, PageToken: params.pageToken, Reverse: params.reverse, KeysOnly: params.keysOnly, MinBlock: params.minBlock}))
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+	copyBlockHeaders(w.Header(), resp.Header())
+	g.writeJSON(w, http.StatusOK, params.kvs(resp.Msg.KeyValues, resp.Msg.LimitReached, resp.Msg.NextPageToken))
+}
+
+func (g *RESTGateway) serveScan(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params, err := g.parseParams(query)
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+
+	req := &kvv1.ScanRequest{Begin: query.Get("begin"), Limit: params.limit, AtBlock: params.atBlock, Consistency: params.consistency, Format: params.format, PageToken: params.pageToken, Reverse: params.reverse, KeysOnly: params.keysOnly, MinBlock: params.minBlock}
+	if query.Has("end") {
+		end := query.Get("end")
+		req.ExclusiveEnd = &end
+	}
+
+	resp, err := g.cs.Scan(r.Context(), connect.NewRequest(req))
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+	copyBlockHeaders(w.Header(), resp.Header())
+	g.writeJSON(w, http.StatusOK, params.kvs(resp.Msg.KeyValues, resp.Msg.LimitReached, resp.Msg.NextPageToken))
+}
+
+func (g *RESTGateway) serveCount(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params, err := g.parseParams(query)
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+
+	req := &kvv1.CountRequest{Prefix: query.Get("prefix"), Begin: query.Get("begin"), MinBlock: params.minBlock}
+	if query.Has("end") {
+		end := query.Get("end")
+		req.ExclusiveEnd = &end
+	}
+
+	resp, err := g.cs.Count(r.Context(), connect.NewRequest(req))
+	if err != nil {
+		g.writeConnectError(w, err)
+		return
+	}
+	copyBlockHeaders(w.Header(), resp.Header())
+	g.writeJSON(w, http.StatusOK, &restCount{Count: resp.Msg.Count, LimitReached: resp.Msg.LimitReached})
+}
+
+// restParams are the query parameters shared by all routes.
+type restParams struct {
+	limit       uint64
+	atBlock     *uint64
+	minBlock    *uint64
+	consistency kvv1.Consistency
+	format      kvv1.Format
+	encoding    ValueEncoding
+	pageToken   string
+	reverse     bool
+	keysOnly    bool
+}
+
+func (g *RESTGateway) parseParams(query url.Values) (*restParams, error) {
+	params := &restParams{encoding: g.encoding, pageToken: query.Get("page_token")}
+
+	if query.Has("limit") {
+		limit, err := strconv.ParseUint(query.Get("limit"), 10, 64)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("request value for 'limit' must be a positive integer, but received %q", query.Get("limit")))
+		}
+		params.limit = limit
+	}
+
+	for _, block := range []struct {
+		name  string
+		value **uint64
+	}{{"at_block", &params.atBlock}, {"min_block", &params.minBlock}} {
+		if !query.Has(block.name) {
+			continue
+		}
+		parsed, err := strconv.ParseUint(query.Get(block.name), 10, 64)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("request value for '%s' must be a block number, but received %q", block.name, query.Get(block.name)))
+		}
+		*block.value = &parsed
+	}
+
+	for _, flag := range []struct {
+		name  string
+		value *bool
+	}{{"reverse", &params.reverse}, {"keys_only", &params.keysOnly}} {
+		if !query.Has(flag.name) {
+			continue
+		}
+		parsed, err := strconv.ParseBool(query.Get(flag.name))
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("request value for '%s' must be a boolean, but received %q", flag.name, query.Get(flag.name)))
+		}
+		*flag.value = parsed
+	}
+
+	switch format := strings.ToLower(query.Get("format")); format {
+	case "", "bytes":
+	case "json":
+		params.format = kvv1.Format_JSON
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("request value for 'format' must be 'bytes' or 'json', but received %q", format))
+	}
+
+	switch consistency := strings.ToLower(query.Get("consistency")); consistency {
+	case "", "latest":
+	case "final":
+		params.consistency = kvv1.Consistency_FINAL
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("request value for 'consistency' must be 'latest' or 'final', but received %q", consistency))
+	}
+
+	if query.Has("encoding") {
+		encoding, err := ParseValueEncoding(query.Get("encoding"))
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		params.encoding = encoding
+	}
+	return params, nil
+}
+
+func (p *restParams) kv(key string, value []byte, jsonValue string) *restKV {
+	out := &restKV{Key: key}
+	if p.keysOnly {
+		return out
+	}
+	if jsonValue != "" {
+		out.JSONValue = json.RawMessage(jsonValue)
+		return out
+	}
+	encoded, encoding := p.encoding.encode(value)
+	out.Value = &encoded
+	if encoding != p.encoding {
+		out.Encoding = encoding
+	}
+	return out
+}
+
+func (p *restParams) kvs(keyVals []*kvv1.KV, limitReached bool, nextPageToken string) *restKVs {
+	out := &restKVs{KeyValues: make([]*restKV, len(keyVals)), LimitReached: limitReached, NextPageToken: nextPageToken}
+	for i, kv := range keyVals {
+		out.KeyValues[i] = p.kv(kv.Key, kv.Value, kv.JsonValue)
+	}
+	return out
+}
+
+// copyBlockHeaders forwards the committed block headers of a Kv response.
+func copyBlockHeaders(dst, src http.Header) {
+	for _, name := range []string{BlockNumberHeader, BlockIDHeader, FinalBlockHeightHeader} {
+		if value := src.Get(name); value != "" {
+			dst.Set(name, value)
+		}
+	}
+}
+
+func (g *RESTGateway) writeConnectError(w http.ResponseWriter, err error) {
+	code := connect.CodeOf(err)
+	message := err.Error()
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		message = connectErr.Message()
+		// e.g. the Retry-After of the rate limited requests
+		for name, values := range connectErr.Meta() {
+			w.Header()[name] = values
+		}
+	}
+	g.writeError(w, httpStatus(code), code.String(), message)
+}
+
+func (g *RESTGateway) writeError(w http.ResponseWriter, status int, code string, message string) {
+	g.writeJSON(w, status, &restError{Code: code, Message: message})
+}
+
+func (g *RESTGateway) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		g.logger.Debug("writing rest response", zap.Error(err))
+	}
+}
+
+// httpStatus is the HTTP status of a Connect code, following the mapping of the
+// Connect protocol.
+func httpStatus(code connect.Code) int {
+	switch code {
+	case connect.CodeCanceled, connect.CodeDeadlineExceeded:
+		return http.StatusRequestTimeout
+	case connect.CodeInvalidArgument, connect.CodeOutOfRange:
+		return http.StatusBadRequest
+	case connect.CodeNotFound, connect.CodeUnimplemented:
+		return http.StatusNotFound
+	case connect.CodeAlreadyExists, connect.CodeAborted:
+		return http.StatusConflict
+	case connect.CodePermissionDenied:
+		return http.StatusForbidden
+	case connect.CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case connect.CodeFailedPrecondition:
+		return http.StatusPreconditionFailed
+	case connect.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case connect.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
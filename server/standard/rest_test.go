@@ -0,0 +1,384 @@
+package standard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/streamingfast/substreams-sink-kv/db"
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/streamingfast/substreams-sink-kv/server/auth"
+	"github.com/streamingfast/substreams-sink-kv/server/ratelimit"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+	"go.uber.org/zap"
+)
+
+// testReader is a db.Reader over an in-memory map, limits being always 2.
+type testReader struct {
+	values map[string][]byte
+	block  *kvv1.CommittedBlock
+}
+
+func (r *testReader) Get(ctx context.Context, key string, opts ...db.ReadOption) ([]byte, error) {
+	value, found := r.values[key]
+	if !found {
+		return nil, db.ErrNotFound
+	}
+	return value, nil
+}
+
+func (r *testReader) GetMany(ctx context.Context, keys []string) ([]*kvv1.GetManyEntry, error) {
+	entries := make([]*kvv1.GetManyEntry, len(keys))
+	for i, key := range keys {
+		value, found := r.values[key]
+		entries[i] = &kvv1.GetManyEntry{Key: key, Value: value, Found: found}
+	}
+	return entries, nil
+}
+
+func (r *testReader) GetByPrefix(ctx context.Context, prefix string, limit int, opts ...db.ReadOption) ([]*kvv1.KV, bool, error) {
+	if prefix == "" {
+		return nil, false, fmt.Errorf("%w: request value for 'prefix' must not be empty", db.ErrInvalidArguments)
+	}
+	return r.Scan(ctx, prefix, prefix+"\xff", limit, opts...)
+}
+
+func (r *testReader) Scan(ctx context.Context, start string, exclusiveEnd string, limit int, opts ...db.ReadOption) ([]*kvv1.KV, bool, error) {
+	if limit < 0 || limit > 2 {
+		return nil, false, fmt.Errorf("%w: request value for 'limit' must be between 1 and 2, but received %d", db.ErrInvalidArguments, limit)
+	}
+	if limit == 0 {
+		limit = 2
+	}
+
+	var keys []string
+	for key := range r.values {
+		if key >= start && (exclusiveEnd == "" || key < exclusiveEnd) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return nil, false, db.ErrNotFound
+	}
+
+	limitReached := len(keys) > limit
+	if limitReached {
+		keys = keys[:limit]
+	}
+	kvs := make([]*kvv1.KV, len(keys))
+	for i, key := range keys {
+		kvs[i] = &kvv1.KV{Key: key, Value: r.values[key]}
+	}
+	return kvs, limitReached, nil
+}
+
+func (r *testReader) Count(ctx context.Context, begin string, exclusiveEnd string) (uint64, bool, error) {
+	var count uint64
+	for key := range r.values {
+		if key >= begin && (exclusiveEnd == "" || key < exclusiveEnd) {
+			count++
+		}
+	}
+	return count, false, nil
+}
+
+func (r *testReader) CountPrefix(ctx context.Context, prefix string) (uint64, bool, error) {
+	if prefix == "" {
+		return 0, false, fmt.Errorf("%w: request value for 'prefix' must not be empty", db.ErrInvalidArguments)
+	}
+	return r.Count(ctx, prefix, prefix+"\xff")
+}
+
+func (r *testReader) CommittedBlock(ctx context.Context) (*kvv1.CommittedBlock, error) {
+	return r.block, nil
+}
+
+func (r *testReader) QueryIndex(ctx context.Context, index string, value string, limit int) ([]string, bool, error) {
+	return nil, false, db.ErrNotFound
+}
+
+func (r *testReader) ReadChanges(ctx context.Context, fromSequence uint64, limit int) ([]*kvv1.ChangeLogEntry, uint64, bool, error) {
+	return nil, fromSequence, false, nil
+}
+
+func TestRESTGateway(t *testing.T) {
+	reader := &testReader{values: map[string][]byte{
+		"a/1":   []byte("one"),
+		"a/2":   []byte("two"),
+		"a/3":   []byte("three"),
+		"b 1":   {0xff, 0x00},
+		"c:key": []byte("c"),
+	}}
+	gateway := NewRESTGateway(NewServer(reader, nil, nil, zap.NewNop(), false), ValueEncodingBase64, zap.NewNop())
+	nextPageToken := func(lastKey string) string {
+		return db.NextPrefixPageToken("a/", false, []*kvv1.KV{{Key: lastKey}}, true)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		expectStatus int
+		expectBody   string
+	}{
+		{"get", "GET", "/v1/kv/a%2F1", 200, `{"key":"a/1","value":"b25l"}`},
+		{"get unescaped slash", "GET", "/v1/kv/a/1", 200, `{"key":"a/1","value":"b25l"}`},
+		{"get escaped space", "GET", "/v1/kv/b%201", 200, `{"key":"b 1","value":"/wA="}`},
+		{"get hex", "GET", "/v1/kv/b%201?encoding=hex", 200, `{"key":"b 1","value":"ff00"}`},
+		{"get raw", "GET", "/v1/kv/a/1?encoding=raw", 200, `{"key":"a/1","value":"one"}`},
+		{"get not found", "GET", "/v1/kv/missing", 404, `{"code":"not_found","message":"requested key not found in database: not found"}`},
+		{"get invalid encoding", "GET", "/v1/kv/a/1?encoding=utf16", 400, `{"code":"invalid_argument","message":"invalid value encoding \"utf16\", must be one of \"base64\", \"hex\" or \"raw\""}`},
+		{"get invalid consistency", "GET", "/v1/kv/a/1?consistency=safe", 400, `{"code":"invalid_argument","message":"request value for 'consistency' must be 'latest' or 'final', but received \"safe\""}`},
+		{"get invalid at_block", "GET", "/v1/kv/a/1?at_block=x", 400, `{"code":"invalid_argument","message":"request value for 'at_block' must be a block number, but received \"x\""}`},
+		{"prefix", "GET", "/v1/prefix/a%2F?encoding=raw", 200, `{"key_values":[{"key":"a/1","value":"one"},{"key":"a/2","value":"two"}],"limit_reached":true,"next_page_token":"` + nextPageToken("a/2") + `"}`},
+		{"prefix limit", "GET", "/v1/prefix/a/?limit=1&encoding=raw", 200, `{"key_values":[{"key":"a/1","value":"one"}],"limit_reached":true,"next_page_token":"` + nextPageToken("a/1") + `"}`},
+		{"prefix limit too high", "GET", "/v1/prefix/a/?limit=3", 400, `{"code":"invalid_argument","message":"invalid arguments: request value for 'limit' must be between 1 and 2, but received 3"}`},
+		{"prefix invalid limit", "GET", "/v1/prefix/a/?limit=-1", 400, `{"code":"invalid_argument","message":"request value for 'limit' must be a positive integer, but received \"-1\""}`},
+		{"prefix invalid reverse", "GET", "/v1/prefix/a/?reverse=maybe", 400, `{"code":"invalid_argument","message":"request value for 'reverse' must be a boolean, but received \"maybe\""}`},
+		{"prefix keys only", "GET", "/v1/prefix/a/?keys_only=true&limit=1", 200, `{"key_values":[{"key":"a/1"}],"limit_reached":true,"next_page_token":"` + nextPageToken("a/1") + `"}`},
+		{"prefix invalid keys_only", "GET", "/v1/prefix/a/?keys_only=1x", 400, `{"code":"invalid_argument","message":"request value for 'keys_only' must be a boolean, but received \"1x\""}`},
+		{"prefix empty", "GET", "/v1/prefix/", 400, `{"code":"invalid_argument","message":"invalid arguments: request value for 'prefix' must not be empty"}`},
+		{"scan", "GET", "/v1/scan?begin=a/3&end=c&encoding=hex", 200, `{"key_values":[{"key":"a/3","value":"7468726565"},{"key":"b 1","value":"ff00"}],"limit_reached":false}`},
+		{"scan raw invalid utf8", "GET", "/v1/scan?begin=a/3&end=c&encoding=raw", 200, `{"key_values":[{"key":"a/3","value":"three"},{"key":"b 1","value":"/wA=","encoding":"base64"}],"limit_reached":false}`},
+		{"get raw invalid utf8", "GET", "/v1/kv/b%201?encoding=raw", 200, `{"key":"b 1","value":"/wA=","encoding":"base64"}`},
+		{"scan without end", "GET", "/v1/scan?begin=b&encoding=hex", 200, `{"key_values":[{"key":"b 1","value":"ff00"},{"key":"c:key","value":"63"}],"limit_reached":false}`},
+		{"scan not found", "GET", "/v1/scan?begin=d", 404, `{"code":"not_found","message":"one of the requested keys was not found in database: not found"}`},
+		{"count prefix", "GET", "/v1/count?prefix=a/", 200, `{"count":3,"limit_reached":false}`},
+		{"count range", "GET", "/v1/count?begin=a/2&end=c", 200, `{"count":3,"limit_reached":false}`},
+		{"count open ended", "GET", "/v1/count?begin=b", 200, `{"count":2,"limit_reached":false}`},
+		{"unknown route", "GET", "/v1/unknown", 404, `{"code":"not_found","message":"no route for /v1/unknown"}`},
+		{"method not allowed", "POST", "/v1/kv/a/1", 405, `{"code":"method_not_allowed","message":"method POST is not allowed, only GET is"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			gateway.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+
+			assert.Equal(t, test.expectStatus, recorder.Code)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.expectBody, strings.TrimSpace(recorder.Body.String()))
+		})
+	}
+}
+
+func TestRESTGateway_JSONFormat(t *testing.T) {
+	valueTypes, err := NewValueTypes([]*kvv1.ValueType{{KeyPrefix: "t:", Type: "google.protobuf.StringValue"}}, nil)
+	require.NoError(t, err)
+
+	reader := &testReader{values: map[string][]byte{
+		"t:1": {0x0a, 0x02, 'h', 'i'},
+		"u:1": []byte("raw"),
+	}}
+	gateway := NewRESTGateway(NewServer(reader, nil, valueTypes, zap.NewNop(), false), ValueEncodingRaw, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/scan?format=json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "t:1", "json_value": "hi"},
+		map[string]interface{}{"key": "u:1", "value": "raw"},
+	}, body["key_values"])
+}
+
+func TestServer_GetManyValues(t *testing.T) {
+	valueTypes, err := NewValueTypes([]*kvv1.ValueType{{KeyPrefix: "t:", Type: "google.protobuf.StringValue"}}, nil)
+	require.NoError(t, err)
+
+	reader := &testReader{values: map[string][]byte{
+		"t:1": {0x0a, 0x02, 'h', 'i'},
+		"u:1": []byte("raw"),
+	}}
+	server := NewServer(reader, nil, valueTypes, zap.NewNop(), false)
+
+	resp, err := server.GetMany(context.Background(), connect.NewRequest(&kvv1.GetManyRequest{Keys: []string{"u:1", "u:2"}}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("raw"), nil}, resp.Msg.Values)
+	assert.Nil(t, resp.Msg.JsonValues)
+
+	resp, err = server.GetMany(context.Background(), connect.NewRequest(&kvv1.GetManyRequest{Keys: []string{"t:1", "u:1"}, Format: kvv1.Format_JSON}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{nil, []byte("raw")}, resp.Msg.Values)
+	assert.Equal(t, []string{`"hi"`, ""}, resp.Msg.JsonValues)
+}
+
+func TestRESTGateway_BlockHeaders(t *testing.T) {
+	reader := &testReader{values: map[string][]byte{"a": []byte("a")}}
+	gateway := NewRESTGateway(NewServer(reader, nil, nil, zap.NewNop(), false), ValueEncodingRaw, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/kv/a", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(BlockNumberHeader))
+
+	reader.block = &kvv1.CommittedBlock{BlockNumber: 12, BlockId: "abc", FinalBlockHeight: 10}
+	for _, path := range []string{"/v1/kv/a", "/v1/prefix/a", "/v1/scan", "/v1/count?prefix=a"} {
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		require.Equal(t, http.StatusOK, recorder.Code, path)
+		assert.Equal(t, "12", recorder.Header().Get(BlockNumberHeader), path)
+		assert.Equal(t, "abc", recorder.Header().Get(BlockIDHeader), path)
+		assert.Equal(t, "10", recorder.Header().Get(FinalBlockHeightHeader), path)
+	}
+}
+
+func TestRESTGateway_MinBlock(t *testing.T) {
+	reader := &testReader{values: map[string][]byte{"a": []byte("a")}}
+	gateway := NewRESTGateway(NewServer(reader, nil, nil, zap.NewNop(), false, WithMinBlockTimeout(10*time.Millisecond)), ValueEncodingRaw, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/kv/a?min_block=10", nil))
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.JSONEq(t, `{"code":"failed_precondition","message":"request value for 'min_block' 10 was not reached within 10ms, last committed block is 0"}`, recorder.Body.String())
+
+	reader.block = &kvv1.CommittedBlock{BlockNumber: 12, BlockId: "abc", FinalBlockHeight: 10}
+	for _, path := range []string{"/v1/kv/a?min_block=10", "/v1/prefix/a?min_block=12", "/v1/scan?min_block=1", "/v1/count?prefix=a&min_block=12"} {
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code, path)
+		assert.Equal(t, "12", recorder.Header().Get(BlockNumberHeader), path)
+	}
+
+	recorder = httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/scan?min_block=13", nil))
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/scan?min_block=x", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRESTGateway_Auth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": [{"name": "explorer", "key": "secret", "prefixes": ["a"]}]}`), 0600))
+	keys, err := auth.LoadAPIKeys(path)
+	require.NoError(t, err)
+
+	reader := &testReader{values: map[string][]byte{"a": []byte("a"), "ab": []byte("ab"), "b": []byte("b")}}
+	gateway := NewRESTGateway(NewServer(reader, nil, nil, zap.NewNop(), false, WithAuthenticator(auth.Authenticators{keys})), ValueEncodingRaw, zap.NewNop())
+
+	request := func(path string, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if key != "" {
+			req.Header.Set("X-Api-Key", key)
+		}
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := request("/v1/kv/a", "")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"code":"unauthenticated","message":"missing or unknown credentials"}`, recorder.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, request("/v1/kv/a", "wrong").Code)
+
+	for _, path := range []string{"/v1/kv/ab", "/v1/prefix/a", "/v1/scan?begin=a&end=b", "/v1/count?prefix=ab"} {
+		assert.Equal(t, http.StatusOK, request(path, "secret").Code, path)
+	}
+
+	recorder = request("/v1/kv/b", "secret")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"code":"permission_denied","message":"\"explorer\" is not allowed to read the requested keys"}`, recorder.Body.String())
+	for _, path := range []string{"/v1/prefix/", "/v1/scan", "/v1/scan?begin=a&end=c", "/v1/count?begin=a"} {
+		assert.Equal(t, http.StatusForbidden, request(path, "secret").Code, path)
+	}
+}
+
+func TestRESTGateway_RateLimit(t *testing.T) {
+	reader := &testReader{values: map[string][]byte{"a": []byte("a"), "ab": []byte("ab")}}
+	gateway := NewRESTGateway(NewServer(reader, nil, nil, zap.NewNop(), false, WithRateLimiter(ratelimit.New(0.001, 3))), ValueEncodingRaw, zap.NewNop())
+
+	request := func(path string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// the scan returns two rows, leaving one token to the client
+	require.Equal(t, http.StatusOK, request("/v1/scan", "10.0.0.1:1234").Code)
+	require.Equal(t, http.StatusOK, request("/v1/kv/a", "10.0.0.1:1234").Code)
+
+	throttled := testutil.ToFloat64(ThrottledRequests.Native().WithLabelValues("Get"))
+	recorder := request("/v1/kv/a", "10.0.0.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "1000", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), `"code":"resource_exhausted","message":"rate limit exceeded, retry in`)
+	assert.Equal(t, throttled+1, testutil.ToFloat64(ThrottledRequests.Native().WithLabelValues("Get")))
+
+	assert.Equal(t, http.StatusOK, request("/v1/kv/a", "10.0.0.2:1234").Code)
+
+	// the rows are reserved up to the limit before reading, the whole budget without limit
+	require.Equal(t, http.StatusOK, request("/v1/kv/a", "10.0.0.3:1234").Code)
+	require.Equal(t, http.StatusOK, request("/v1/kv/a", "10.0.0.3:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("/v1/scan", "10.0.0.3:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("/v1/scan?limit=2", "10.0.0.3:1234").Code)
+	require.Equal(t, http.StatusOK, request("/v1/scan?limit=1", "10.0.0.3:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("/v1/kv/a", "10.0.0.3:1234").Code)
+}
+
+func TestServer_RateLimitQueryLimits(t *testing.T) {
+	reader := &testReader{values: map[string][]byte{"a": []byte("a"), "ab": []byte("ab"), "b": []byte("b")}}
+	server := NewServer(reader, nil, nil, zap.NewNop(), false, WithRateLimiter(ratelimit.New(0.001, 10)), WithQueryLimits(2, 4))
+	peer := connect.Peer{Addr: "10.0.0.1:1234"}
+
+	request := func(msg *kvv1.ScanRequest) error {
+		_, err := server.rateLimit(context.Background(), peer, "Scan", server.expectedRows(msg.Limit, msg.KeysOnly), zap.NewNop())
+		return err
+	}
+
+	assert.Equal(t, 2, server.expectedRows(0, false))
+	assert.Equal(t, 4, server.expectedRows(0, true))
+	assert.Equal(t, 3, server.expectedRows(3, false))
+
+	// reservations left unsettled keep the whole expected cost
+	require.NoError(t, request(&kvv1.ScanRequest{KeysOnly: true}))
+	require.NoError(t, request(&kvv1.ScanRequest{Limit: 3}))
+	require.NoError(t, request(&kvv1.ScanRequest{}))
+	assert.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(request(&kvv1.ScanRequest{})))
+	require.NoError(t, request(&kvv1.ScanRequest{Limit: 1}))
+}
+
+func TestRESTGateway_RateLimitCount(t *testing.T) {
+	reader := &testReader{values: map[string][]byte{"a": []byte("a"), "ab": []byte("ab"), "b": []byte("b")}}
+	gateway := NewRESTGateway(NewServer(reader, nil, nil, zap.NewNop(), false, WithRateLimiter(ratelimit.New(0.001, 10)), WithQueryLimits(2, 4)), ValueEncodingRaw, zap.NewNop())
+
+	request := func(path string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// counts reserve the keys limit and are charged the three keys scanned
+	require.Equal(t, http.StatusOK, request("/v1/count?begin=a"))
+	require.Equal(t, http.StatusOK, request("/v1/count?begin=a"))
+	require.Equal(t, http.StatusOK, request("/v1/scan?limit=2"))
+	require.Equal(t, http.StatusOK, request("/v1/kv/a"))
+	require.Equal(t, http.StatusOK, request("/v1/kv/a"))
+	assert.Equal(t, http.StatusTooManyRequests, request("/v1/kv/a"))
+}
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, httpStatus(connect.CodeNotFound))
+	assert.Equal(t, http.StatusTooManyRequests, httpStatus(connect.CodeResourceExhausted))
+	assert.Equal(t, http.StatusUnauthorized, httpStatus(connect.CodeUnauthenticated))
+	assert.Equal(t, http.StatusInternalServerError, httpStatus(connect.CodeInternal))
+}
@@ -0,0 +1,103 @@
+package standard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/streamingfast/substreams-sink-kv/protofiles"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ValueTypes decodes values to JSON with the types declared in the sink config, the
+// type of a value being the one of the longest declared prefix of its key.
+type ValueTypes struct {
+	configs           []*kvv1.ValueType
+	byPrefix          []valueType
+	marshalOptions    protojson.MarshalOptions
+	fileDescriptorSet []byte
+}
+
+type valueType struct {
+	keyPrefix string
+	message   protoreflect.MessageDescriptor
+}
+
+// NewValueTypes resolves the declared types against the spkg proto files.
+func NewValueTypes(configs []*kvv1.ValueType, protoFiles []*descriptorpb.FileDescriptorProto) (*ValueTypes, error) {
+	files, err := protofiles.New(protoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("building spkg proto files: %w", err)
+	}
+
+	v := &ValueTypes{
+		configs:        configs,
+		marshalOptions: protojson.MarshalOptions{Resolver: dynamicpb.NewTypes(files)},
+	}
+
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	added := map[string]bool{}
+	var addFile func(file protoreflect.FileDescriptor)
+	addFile = func(file protoreflect.FileDescriptor) {
+		if added[file.Path()] {
+			return
+		}
+		added[file.Path()] = true
+		for i := 0; i < file.Imports().Len(); i++ {
+			addFile(file.Imports().Get(i).FileDescriptor)
+		}
+		fileSet.File = append(fileSet.File, protodesc.ToFileDescriptorProto(file))
+	}
+
+	for _, config := range configs {
+		message, err := protofiles.FindMessage(files, config.Type)
+		if err != nil {
+			return nil, fmt.Errorf("value type of prefix %q: %w", config.KeyPrefix, err)
+		}
+
+		v.byPrefix = append(v.byPrefix, valueType{keyPrefix: config.KeyPrefix, message: message})
+		addFile(message.ParentFile())
+	}
+	sort.SliceStable(v.byPrefix, func(i, j int) bool {
+		return len(v.byPrefix[i].keyPrefix) > len(v.byPrefix[j].keyPrefix)
+	})
+
+	if v.fileDescriptorSet, err = proto.Marshal(fileSet); err != nil {
+		return nil, fmt.Errorf("marshalling file descriptor set: %w", err)
+	}
+	return v, nil
+}
+
+// toJSON decodes value, the value of key, returning false if key has no declared type
+// or value cannot be decoded with it.
+func (v *ValueTypes) toJSON(key string, value []byte, logger *zap.Logger) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+
+	for _, valueType := range v.byPrefix {
+		if !strings.HasPrefix(key, valueType.keyPrefix) {
+			continue
+		}
+
+		message := dynamicpb.NewMessage(valueType.message)
+		if err := proto.Unmarshal(value, message); err != nil {
+			logger.Debug("value cannot be decoded, returning bytes", zap.String("key", key), zap.Error(err))
+			return "", false
+		}
+		out, err := v.marshalOptions.Marshal(message)
+		if err != nil {
+			logger.Debug("value cannot be marshalled to json, returning bytes", zap.String("key", key), zap.Error(err))
+			return "", false
+		}
+		return string(out), true
+	}
+	return "", false
+}
@@ -0,0 +1,80 @@
+package standard
+
+import (
+	"testing"
+	"time"
+
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func transferProtoFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/v1/transfer.proto"),
+		Package:    proto.String("test.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Transfer"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("from"), JsonName: proto.String("from"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("at"), JsonName: proto.String("at"), Number: proto.Int32(2), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Timestamp")},
+			},
+		}},
+	}
+}
+
+func TestValueTypes(t *testing.T) {
+	_, err := NewValueTypes([]*kvv1.ValueType{{KeyPrefix: "t:", Type: "test.v1.Missing"}}, []*descriptorpb.FileDescriptorProto{transferProtoFile()})
+	require.Error(t, err)
+
+	valueTypes, err := NewValueTypes([]*kvv1.ValueType{
+		{KeyPrefix: "t:", Type: "test.v1.Transfer"},
+		{KeyPrefix: "t:at:", Type: "google.protobuf.Timestamp"},
+	}, []*descriptorpb.FileDescriptorProto{transferProtoFile()})
+	require.NoError(t, err)
+
+	// dependencies come first
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, proto.Unmarshal(valueTypes.fileDescriptorSet, fileSet))
+	require.Len(t, fileSet.File, 2)
+	assert.Equal(t, "google/protobuf/timestamp.proto", fileSet.File[0].GetName())
+	assert.Equal(t, "test/v1/transfer.proto", fileSet.File[1].GetName())
+
+	timestamp, err := proto.Marshal(timestamppb.New(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	transfer := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), "alice")
+	transfer = protowire.AppendBytes(protowire.AppendTag(transfer, 2, protowire.BytesType), timestamp)
+
+	tests := []struct {
+		name       string
+		key        string
+		value      []byte
+		expectJSON string
+	}{
+		{"declared type", "t:1", transfer, `{"from":"alice","at":"2023-01-01T00:00:00Z"}`},
+		{"longest prefix", "t:at:1", timestamp, `"2023-01-01T00:00:00Z"`},
+		{"undeclared type", "other:1", transfer, ""},
+		{"invalid value", "t:1", []byte{0xFF}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsonValue, ok := valueTypes.toJSON(test.key, test.value, zap.NewNop())
+			require.Equal(t, test.expectJSON != "", ok)
+			if ok {
+				assert.JSONEq(t, test.expectJSON, jsonValue)
+			}
+		})
+	}
+
+	var noValueTypes *ValueTypes
+	_, ok := noValueTypes.toJSON("t:1", transfer, zap.NewNop())
+	assert.False(t, ok)
+}
@@ -0,0 +1,91 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"github.com/streamingfast/substreams-sink-kv/db"
+	"go.uber.org/zap"
+)
+
+// Call holds the state of a single invocation of a WASM query module entrypoint, it
+// is reachable from host functions through the context.
+type Call struct {
+	Entrypoint string
+	DBReader   db.Reader
+	Logger     *zap.Logger
+
+	returnValue []byte
+	err         *connect.Error
+	panicErr    error
+}
+
+func (c *Call) SetReturnValue(value []byte) {
+	// The memory backing value is released with the instance, so we keep a copy
+	c.returnValue = append([]byte{}, value...)
+}
+
+func (c *Call) SetError(code connect.Code, message string) {
+	c.err = connect.NewError(code, errors.New(message))
+}
+
+func (c *Call) SetPanicError(message string, filename string, lineNo int, colNo int) {
+	c.panicErr = fmt.Errorf("panic in the wasm query module: %q at %s:%d:%d", message, filename, lineNo, colNo)
+}
+
+type callContextKey struct{}
+
+func withCall(ctx context.Context, call *Call) context.Context {
+	return context.WithValue(ctx, callContextKey{}, call)
+}
+
+func callFromContext(ctx context.Context) *Call {
+	return ctx.Value(callContextKey{}).(*Call)
+}
+
+// Execute runs the entrypoint of a fresh instance of the module with the request bytes
+// and returns the bytes the module outputted. An error set by the module through
+// `set_error` is returned as is, as a `*connect.Error`.
+func (m *Module) Execute(ctx context.Context, call *Call, request []byte) ([]byte, error) {
+	inst, err := m.instantiate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate wasm module: %w", err)
+	}
+	defer inst.Close(ctx)
+
+	f := inst.ExportedFunction(call.Entrypoint)
+	if f == nil {
+		return nil, fmt.Errorf("could not find entrypoint function %q", call.Entrypoint)
+	}
+
+	ctx = withCall(ctx, call)
+
+	ptr, err := writeToHeap(ctx, inst, request)
+	if err != nil {
+		return nil, fmt.Errorf("writing request to heap: %w", err)
+	}
+
+	if _, err := f.Call(ctx, uint64(ptr), uint64(len(request))); err != nil {
+		if call.panicErr != nil {
+			return nil, call.panicErr
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, connect.NewError(contextErrorCode(ctxErr), fmt.Errorf("call aborted: %w", ctxErr))
+		}
+		return nil, fmt.Errorf("call: %w", err)
+	}
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return call.returnValue, nil
+}
+
+func contextErrorCode(err error) connect.Code {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return connect.CodeDeadlineExceeded
+	}
+	return connect.CodeCanceled
+}
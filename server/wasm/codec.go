@@ -0,0 +1,79 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// codecs are the Connect codecs of the WASM query service, they are the default ones
+// resolving `Any` and extensions against the spkg types instead of the global registry.
+func codecs(types *typeResolver) []connect.HandlerOption {
+	return []connect.HandlerOption{
+		connect.WithCodec(&protoCodec{types: types}),
+		connect.WithCodec(&jsonCodec{name: "json", types: types}),
+		connect.WithCodec(&jsonCodec{name: "json; charset=utf-8", types: types}),
+	}
+}
+
+type protoCodec struct {
+	types *typeResolver
+}
+
+func (c *protoCodec) Name() string { return "proto" }
+
+func (c *protoCodec) Marshal(message any) ([]byte, error) {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return nil, errNotProto(message)
+	}
+	return proto.Marshal(protoMessage)
+}
+
+func (c *protoCodec) Unmarshal(data []byte, message any) error {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return errNotProto(message)
+	}
+	if err := (proto.UnmarshalOptions{Resolver: c.types}).Unmarshal(data, protoMessage); err != nil {
+		return fmt.Errorf("unmarshal into %T: %w", message, err)
+	}
+	return nil
+}
+
+type jsonCodec struct {
+	name  string
+	types *typeResolver
+}
+
+func (c *jsonCodec) Name() string { return c.name }
+
+func (c *jsonCodec) Marshal(message any) ([]byte, error) {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return nil, errNotProto(message)
+	}
+	return protojson.MarshalOptions{Resolver: c.types}.Marshal(protoMessage)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, message any) error {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return errNotProto(message)
+	}
+	if len(data) == 0 {
+		return errors.New("zero-length payload is not a valid JSON object")
+	}
+	// unknown fields are discarded, as done by the default Connect codec
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true, Resolver: c.types}).Unmarshal(data, protoMessage); err != nil {
+		return fmt.Errorf("unmarshal into %T: %w", message, err)
+	}
+	return nil
+}
+
+func errNotProto(message any) error {
+	return fmt.Errorf("%T doesn't implement proto.Message", message)
+}
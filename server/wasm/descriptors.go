@@ -0,0 +1,148 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func findService(files *protoregistry.Files, fqGrpcService string) (protoreflect.ServiceDescriptor, error) {
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(fqGrpcService))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find service %q in spkg proto files", fqGrpcService)
+	}
+
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", fqGrpcService)
+	}
+	return service, nil
+}
+
+// newTypes builds the `dynamicpb` types of the messages, enums and extensions declared
+// by the spkg proto files. They are kept out of the global registry, the spkg being
+// free to declare types conflicting with the ones linked in this binary.
+func newTypes(files *protoregistry.Files) (*typeResolver, error) {
+	types := new(protoregistry.Types)
+
+	registerEnums := func(enums protoreflect.EnumDescriptors) error {
+		for i := 0; i < enums.Len(); i++ {
+			if err := types.RegisterEnum(dynamicpb.NewEnumType(enums.Get(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	registerExtensions := func(extensions protoreflect.ExtensionDescriptors) error {
+		for i := 0; i < extensions.Len(); i++ {
+			if err := types.RegisterExtension(dynamicpb.NewExtensionType(extensions.Get(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var registerMessages func(messages protoreflect.MessageDescriptors) error
+	registerMessages = func(messages protoreflect.MessageDescriptors) error {
+		for i := 0; i < messages.Len(); i++ {
+			message := messages.Get(i)
+			if message.IsMapEntry() {
+				continue
+			}
+			if err := types.RegisterMessage(dynamicpb.NewMessageType(message)); err != nil {
+				return err
+			}
+			if err := registerMessages(message.Messages()); err != nil {
+				return err
+			}
+			if err := registerEnums(message.Enums()); err != nil {
+				return err
+			}
+			if err := registerExtensions(message.Extensions()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		err = registerMessages(file.Messages())
+		if err == nil {
+			err = registerEnums(file.Enums())
+		}
+		if err == nil {
+			err = registerExtensions(file.Extensions())
+		}
+		if err != nil {
+			err = fmt.Errorf("registering types of %q: %w", file.Path(), err)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &typeResolver{types}, nil
+}
+
+// typeResolver resolves against the spkg types first and against the global registry
+// afterwards, the way `protofiles` resolves the imports of the spkg proto files.
+type typeResolver struct {
+	types *protoregistry.Types
+}
+
+func (r *typeResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	messageType, err := r.types.FindMessageByName(name)
+	if errors.Is(err, protoregistry.NotFound) {
+		return protoregistry.GlobalTypes.FindMessageByName(name)
+	}
+	return messageType, err
+}
+
+func (r *typeResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	messageType, err := r.types.FindMessageByURL(url)
+	if errors.Is(err, protoregistry.NotFound) {
+		return protoregistry.GlobalTypes.FindMessageByURL(url)
+	}
+	return messageType, err
+}
+
+func (r *typeResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	extensionType, err := r.types.FindExtensionByName(field)
+	if errors.Is(err, protoregistry.NotFound) {
+		return protoregistry.GlobalTypes.FindExtensionByName(field)
+	}
+	return extensionType, err
+}
+
+func (r *typeResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	extensionType, err := r.types.FindExtensionByNumber(message, field)
+	if errors.Is(err, protoregistry.NotFound) {
+		return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+	}
+	return extensionType, err
+}
+
+// RangeExtensionsByMessage visits the spkg extensions of message, then the global ones
+// not shadowed by them.
+func (r *typeResolver) RangeExtensionsByMessage(message protoreflect.FullName, f func(protoreflect.ExtensionType) bool) {
+	seen := make(map[protoreflect.FieldNumber]bool)
+	stopped := false
+	r.types.RangeExtensionsByMessage(message, func(extensionType protoreflect.ExtensionType) bool {
+		seen[extensionType.TypeDescriptor().Number()] = true
+		stopped = !f(extensionType)
+		return !stopped
+	})
+	if stopped {
+		return
+	}
+	protoregistry.GlobalTypes.RangeExtensionsByMessage(message, func(extensionType protoreflect.ExtensionType) bool {
+		if seen[extensionType.TypeDescriptor().Number()] {
+			return true
+		}
+		return f(extensionType)
+	})
+}
@@ -0,0 +1,47 @@
+package wasm
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"github.com/tetratelabs/wazero/api"
+)
+
+var envFuncs = []funcs{
+	{
+		"register_panic",
+		[]api.ValueType{i32, i32, i32, i32, i32, i32},
+		[]api.ValueType{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			message := readStringFromStack(mod, stack[0:])
+			lineNo, colNo := uint32(stack[4]), uint32(stack[5])
+			var filename string
+			if filePtr := stack[2]; filePtr != 0 {
+				filename = readStringFromStack(mod, stack[2:])
+			}
+
+			callFromContext(ctx).SetPanicError(message, filename, int(lineNo), int(colNo))
+		}),
+	},
+	{
+		"output",
+		[]api.ValueType{i32, i32},
+		[]api.ValueType{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			callFromContext(ctx).SetReturnValue(readBytesFromStack(mod, stack[0:]))
+		}),
+	},
+	{
+		"set_error",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{},
+		api.GoModuleFunc(func(ctx context.Context, mod api.Module, stack []uint64) {
+			code := connect.Code(uint32(stack[0]))
+			if code < connect.CodeCanceled || code > connect.CodeUnauthenticated {
+				code = connect.CodeUnknown
+			}
+
+			callFromContext(ctx).SetError(code, readStringFromStack(mod, stack[1:]))
+		}),
+	},
+}
@@ -0,0 +1,205 @@
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"github.com/streamingfast/substreams-sink-kv/db"
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/tetratelabs/wazero/api"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// The `kv` host module gives WASM query modules read access to the store, it is
+// the same API as the `sf.substreams.sink.kv.v1.Kv` service. Every function has the
+// signature `(request_ptr, request_len, output_ptr) -> status`:
+//
+//   - the request is the protobuf encoded request message of the matching `Kv` method
+//   - the returned status is a gRPC status code, 0 (OK) on success
+//   - on success, the protobuf encoded response message is written to memory obtained
+//     from the module's `alloc` export, its pointer and length are then written as two
+//     little endian u32 at `output_ptr`
+//   - on failure, the error message is returned the same way, so that it can be
+//     forwarded as is through `env.set_error`
+//
+// Limits follow the `Kv` service, a `limit` of 0 means the server's query rows limit
+// and a greater one is an INVALID_ARGUMENT error. The `format` of read requests is
+// ignored, values are always returned as bytes.
+var kvFuncs = []funcs{
+	{
+		"get",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{i32},
+		kvHostFunc(&kvv1.GetRequest{}, func(ctx context.Context, reader db.Reader, req *kvv1.GetRequest) (proto.Message, error) {
+			value, err := reader.Get(ctx, req.Key, readOptions(req.AtBlock, req.Consistency, "", false, false)...)
+			if err != nil {
+				return nil, err
+			}
+			return &kvv1.GetResponse{Value: value}, nil
+		}),
+	},
+	{
+		"get_many",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{i32},
+		kvHostFunc(&kvv1.GetManyRequest{}, func(ctx context.Context, reader db.Reader, req *kvv1.GetManyRequest) (proto.Message, error) {
+			entries, err := reader.GetMany(ctx, req.Keys)
+			if err != nil {
+				return nil, err
+			}
+			// the deprecated values are still returned, for the modules predating entries
+			values := make([][]byte, len(entries))
+			for i, entry := range entries {
+				values[i] = entry.Value
+			}
+			return &kvv1.GetManyResponse{Values: values, Entries: entries}, nil
+		}),
+	},
+	{
+		"get_by_prefix",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{i32},
+		kvHostFunc(&kvv1.GetByPrefixRequest{}, func(ctx context.Context, reader db.Reader, req *kvv1.GetByPrefixRequest) (proto.Message, error) {
+			keyValues, limitReached, err := reader.GetByPrefix(ctx, req.Prefix, int(req.Limit), readOptions(req.AtBlock, req.Consistency, req.PageToken, req.Reverse, req.KeysOnly)...)
+			if err != nil {
+				return nil, err
+			}
+			return &kvv1.GetByPrefixResponse{
+				KeyValues:     keyValues,
+				LimitReached:  limitReached,
+				NextPageToken: db.NextPrefixPageToken(req.Prefix, req.Reverse, keyValues, limitReached),
+			}, nil
+		}),
+	},
+	{
+		"scan",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{i32},
+		kvHostFunc(&kvv1.ScanRequest{}, func(ctx context.Context, reader db.Reader, req *kvv1.ScanRequest) (proto.Message, error) {
+			keyValues, limitReached, err := reader.Scan(ctx, req.Begin, req.GetExclusiveEnd(), int(req.Limit), readOptions(req.AtBlock, req.Consistency, req.PageToken, req.Reverse, req.KeysOnly)...)
+			if err != nil {
+				return nil, err
+			}
+			return &kvv1.ScanResponse{
+				KeyValues:     keyValues,
+				LimitReached:  limitReached,
+				NextPageToken: db.NextScanPageToken(req.Begin, req.GetExclusiveEnd(), req.Reverse, keyValues, limitReached),
+			}, nil
+		}),
+	},
+	{
+		"query_index",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{i32},
+		kvHostFunc(&kvv1.QueryIndexRequest{}, func(ctx context.Context, reader db.Reader, req *kvv1.QueryIndexRequest) (proto.Message, error) {
+			keys, limitReached, err := reader.QueryIndex(ctx, req.Index, req.Value, int(req.Limit))
+			if err != nil {
+				return nil, err
+			}
+			return &kvv1.QueryIndexResponse{Keys: keys, LimitReached: limitReached}, nil
+		}),
+	},
+	{
+		"count",
+		[]api.ValueType{i32, i32, i32},
+		[]api.ValueType{i32},
+		kvHostFunc(&kvv1.CountRequest{}, func(ctx context.Context, reader db.Reader, req *kvv1.CountRequest) (proto.Message, error) {
+			count, limitReached, err := countKeys(ctx, reader, req)
+			if err != nil {
+				return nil, err
+			}
+			return &kvv1.CountResponse{Count: count, LimitReached: limitReached}, nil
+		}),
+	},
+}
+
+func kvHostFunc[T proto.Message](request T, handler func(ctx context.Context, reader db.Reader, req T) (proto.Message, error)) api.GoModuleFunc {
+	return func(ctx context.Context, mod api.Module, stack []uint64) {
+		call := callFromContext(ctx)
+		outputPtr := uint32(stack[2])
+
+		req := proto.Clone(request).(T)
+		if err := proto.Unmarshal(readBytesFromStack(mod, stack[0:]), req); err != nil {
+			stack[0] = writeKVStatus(ctx, mod, outputPtr, connect.CodeInvalidArgument, fmt.Sprintf("invalid request: %s", err))
+			return
+		}
+
+		resp, err := handler(ctx, call.DBReader, req)
+		if err != nil {
+			code, message := kvErrorStatus(err)
+			if code == connect.CodeInternal {
+				call.Logger.Info("internal error", zap.Error(err))
+			}
+			stack[0] = writeKVStatus(ctx, mod, outputPtr, code, message)
+			return
+		}
+
+		data, err := proto.Marshal(resp)
+		if err != nil {
+			panic(fmt.Errorf("marshalling response: %w", err))
+		}
+		writeOutput(ctx, mod, outputPtr, data)
+		stack[0] = 0
+	}
+}
+
+func countKeys(ctx context.Context, reader db.Reader, req *kvv1.CountRequest) (uint64, bool, error) {
+	if req.Prefix != "" {
+		return reader.CountPrefix(ctx, req.Prefix)
+	}
+	return reader.Count(ctx, req.Begin, req.GetExclusiveEnd())
+}
+
+func readOptions(atBlock *uint64, consistency kvv1.Consistency, pageToken string, reverse bool, keysOnly bool) (opts []db.ReadOption) {
+	if atBlock != nil {
+		opts = append(opts, db.AtBlock(*atBlock))
+	}
+	if consistency == kvv1.Consistency_FINAL {
+		opts = append(opts, db.Final())
+	}
+	if pageToken != "" {
+		opts = append(opts, db.PageToken(pageToken))
+	}
+	if reverse {
+		opts = append(opts, db.Reverse())
+	}
+	if keysOnly {
+		opts = append(opts, db.KeysOnly())
+	}
+	return opts
+}
+
+func kvErrorStatus(err error) (connect.Code, string) {
+	if errors.Is(err, db.ErrNotFound) {
+		return connect.CodeNotFound, err.Error()
+	}
+	if errors.Is(err, db.ErrInvalidArguments) {
+		return connect.CodeInvalidArgument, err.Error()
+	}
+	return connect.CodeInternal, "internal server error"
+}
+
+func writeKVStatus(ctx context.Context, mod api.Module, outputPtr uint32, code connect.Code, message string) uint64 {
+	writeOutput(ctx, mod, outputPtr, []byte(message))
+	return uint64(code)
+}
+
+// writeOutput copies data to the module's heap and writes the resulting pointer and
+// length at outputPtr. Failures are module faults, the panic aborts the call.
+func writeOutput(ctx context.Context, mod api.Module, outputPtr uint32, data []byte) {
+	ptr, err := writeToHeap(ctx, mod, data)
+	if err != nil {
+		panic(fmt.Errorf("writing output to heap: %w", err))
+	}
+
+	var out [8]byte
+	binary.LittleEndian.PutUint32(out[0:4], ptr)
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(data)))
+	if ok := mod.Memory().Write(outputPtr, out[:]); !ok {
+		panic(fmt.Errorf("could not write output pointer at %d", outputPtr))
+	}
+}
@@ -0,0 +1,254 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/streamingfast/bstream"
+	_ "github.com/streamingfast/kvdb/store/badger3"
+	"github.com/streamingfast/logging"
+	sink "github.com/streamingfast/substreams-sink"
+	"github.com/streamingfast/substreams-sink-kv/db"
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// The Rust guest fixture is committed, it is rebuilt with the wasm32-unknown-unknown
+// Rust target installed.
+//go:generate sh -c "cd testdata/kv_guest && cargo build --target wasm32-unknown-unknown --release && cp target/wasm32-unknown-unknown/release/kv_guest.wasm .."
+
+func TestKVHostFunctions(t *testing.T) {
+	ctx := context.Background()
+	dbPath := "/tmp/substreams-sink-kv-wasm-kv"
+
+	_, tracer := logging.PackageLogger("wasm", "github.com/streamingfast/substreams-sink-kv/server/wasm.test")
+
+	require.NoError(t, os.RemoveAll(dbPath))
+	index, err := db.NewIndex("by_from", "0.", testTransferType(t), "from")
+	require.NoError(t, err)
+	kvDB, err := db.New(fmt.Sprintf("badger3://%s", dbPath), 2, zap.NewNop(), tracer, db.WithIndexes(index))
+	require.NoError(t, err)
+
+	require.NoError(t, kvDB.HandleOperations(ctx, bstream.NewBlockRef("block-1", 1), 0, bstream.StepNew, &kvv1.KVOperations{
+		Operations: []*kvv1.KVOperation{
+			{Key: "a.1", Value: []byte("v1"), Type: kvv1.KVOperation_SET},
+			{Key: "a.2", Value: []byte("v2"), Type: kvv1.KVOperation_SET},
+			{Key: "a.3", Value: []byte("v3"), Type: kvv1.KVOperation_SET},
+			{Key: "b.1", Value: []byte("v4"), Type: kvv1.KVOperation_SET},
+			{Key: "0.1", Value: testTransfer(t, "alice"), Type: kvv1.KVOperation_SET},
+			{Key: "0.2", Value: testTransfer(t, "bob"), Type: kvv1.KVOperation_SET},
+			{Key: "0.3", Value: testTransfer(t, "alice"), Type: kvv1.KVOperation_SET},
+		},
+	}))
+	block := bstream.NewBlockRef("block-1", 1)
+	_, err = kvDB.Flush(ctx, &sink.Cursor{Cursor: &bstream.Cursor{Step: bstream.StepNew, Block: block, LIB: bstream.NewBlockRef("block-0", 0), HeadBlock: block}})
+	require.NoError(t, err)
+
+	nextPageToken := db.NextPrefixPageToken("a.", false, []*kvv1.KV{{Key: "a.2"}}, true)
+
+	cases := []struct {
+		name          string
+		entrypoint    string
+		request       proto.Message
+		expect        proto.Message
+		expectErrCode connect.Code
+	}{
+		{
+			name:       "get",
+			entrypoint: "Get",
+			request:    &kvv1.GetRequest{Key: "a.1"},
+			expect:     &kvv1.GetResponse{Value: []byte("v1")},
+		},
+		{
+			name:          "get not found",
+			entrypoint:    "Get",
+			request:       &kvv1.GetRequest{Key: "c.1"},
+			expectErrCode: connect.CodeNotFound,
+		},
+		{
+			// block 1 is above the final block height, its changes are excluded
+			name:          "get final",
+			entrypoint:    "Get",
+			request:       &kvv1.GetRequest{Key: "a.1", Consistency: kvv1.Consistency_FINAL},
+			expectErrCode: connect.CodeNotFound,
+		},
+		{
+			name:       "get many",
+			entrypoint: "GetMany",
+			request:    &kvv1.GetManyRequest{Keys: []string{"a.2", "b.1"}},
+			expect: &kvv1.GetManyResponse{
+				Values: [][]byte{[]byte("v2"), []byte("v4")},
+				Entries: []*kvv1.GetManyEntry{
+					{Key: "a.2", Value: []byte("v2"), Found: true},
+					{Key: "b.1", Value: []byte("v4"), Found: true},
+				},
+			},
+		},
+		{
+			name:       "get many with missing keys",
+			entrypoint: "GetMany",
+			request:    &kvv1.GetManyRequest{Keys: []string{"c.1", "a.1"}},
+			expect: &kvv1.GetManyResponse{
+				Values: [][]byte{nil, []byte("v1")},
+				Entries: []*kvv1.GetManyEntry{
+					{Key: "c.1"},
+					{Key: "a.1", Value: []byte("v1"), Found: true},
+				},
+			},
+		},
+		{
+			name:          "get many above query rows limit",
+			entrypoint:    "GetMany",
+			request:       &kvv1.GetManyRequest{Keys: []string{"a.1", "a.2", "a.3"}},
+			expectErrCode: connect.CodeInvalidArgument,
+		},
+		{
+			name:          "get many without keys",
+			entrypoint:    "GetMany",
+			request:       &kvv1.GetManyRequest{},
+			expectErrCode: connect.CodeInvalidArgument,
+		},
+		{
+			name:       "get by prefix",
+			entrypoint: "GetByPrefix",
+			request:    &kvv1.GetByPrefixRequest{Prefix: "b."},
+			expect: &kvv1.GetByPrefixResponse{KeyValues: []*kvv1.KV{
+				{Key: "b.1", Value: []byte("v4")},
+			}},
+		},
+		{
+			name:       "get by prefix defaults to query rows limit",
+			entrypoint: "GetByPrefix",
+			request:    &kvv1.GetByPrefixRequest{Prefix: "a."},
+			expect: &kvv1.GetByPrefixResponse{KeyValues: []*kvv1.KV{
+				{Key: "a.1", Value: []byte("v1")},
+				{Key: "a.2", Value: []byte("v2")},
+			}, LimitReached: true, NextPageToken: nextPageToken},
+		},
+		{
+			name:       "get by prefix resumes from page token",
+			entrypoint: "GetByPrefix",
+			request:    &kvv1.GetByPrefixRequest{Prefix: "a.", PageToken: nextPageToken},
+			expect: &kvv1.GetByPrefixResponse{KeyValues: []*kvv1.KV{
+				{Key: "a.3", Value: []byte("v3")},
+			}},
+		},
+		{
+			// badger3 stores only scan forward
+			name:          "get by prefix in reverse",
+			entrypoint:    "GetByPrefix",
+			request:       &kvv1.GetByPrefixRequest{Prefix: "a.", Limit: 1, Reverse: true},
+			expectErrCode: connect.CodeInvalidArgument,
+		},
+		{
+			name:          "get by prefix above query rows limit",
+			entrypoint:    "GetByPrefix",
+			request:       &kvv1.GetByPrefixRequest{Prefix: "a.", Limit: 3},
+			expectErrCode: connect.CodeInvalidArgument,
+		},
+		{
+			name:       "scan",
+			entrypoint: "Scan",
+			request:    &kvv1.ScanRequest{Begin: "a.3", ExclusiveEnd: proto.String("b.2"), Limit: 2},
+			expect: &kvv1.ScanResponse{KeyValues: []*kvv1.KV{
+				{Key: "a.3", Value: []byte("v3")},
+				{Key: "b.1", Value: []byte("v4")},
+			}},
+		},
+		{
+			name:          "scan not found",
+			entrypoint:    "Scan",
+			request:       &kvv1.ScanRequest{Begin: "c."},
+			expectErrCode: connect.CodeNotFound,
+		},
+		{
+			name:       "query index",
+			entrypoint: "QueryIndex",
+			request:    &kvv1.QueryIndexRequest{Index: "by_from", Value: "alice"},
+			expect:     &kvv1.QueryIndexResponse{Keys: []string{"0.1", "0.3"}},
+		},
+		{
+			name:          "query unknown index",
+			entrypoint:    "QueryIndex",
+			request:       &kvv1.QueryIndexRequest{Index: "by_to", Value: "alice"},
+			expectErrCode: connect.CodeInvalidArgument,
+		},
+		{
+			name:       "count prefix",
+			entrypoint: "Count",
+			request:    &kvv1.CountRequest{Prefix: "b."},
+			expect:     &kvv1.CountResponse{Count: 1},
+		},
+		{
+			name:       "count stops at query keys limit",
+			entrypoint: "Count",
+			request:    &kvv1.CountRequest{Prefix: "a."},
+			expect:     &kvv1.CountResponse{Count: 2, LimitReached: true},
+		},
+		{
+			name:       "count range",
+			entrypoint: "Count",
+			request:    &kvv1.CountRequest{Begin: "a.3", ExclusiveEnd: proto.String("b.2")},
+			expect:     &kvv1.CountResponse{Count: 2},
+		},
+	}
+
+	for _, fixture := range []string{"testdata/kv.wasm", "testdata/kv_guest.wasm"} {
+		t.Run(fixture, func(t *testing.T) {
+			module := newTestModule(t, fixture)
+
+			for _, c := range cases {
+				t.Run(c.name, func(t *testing.T) {
+					request, err := proto.Marshal(c.request)
+					require.NoError(t, err)
+
+					out, err := module.Execute(ctx, &Call{Entrypoint: c.entrypoint, DBReader: kvDB, Logger: zap.NewNop()}, request)
+					if c.expectErrCode != 0 {
+						require.Error(t, err)
+						assert.Equal(t, c.expectErrCode, connect.CodeOf(err))
+						return
+					}
+					require.NoError(t, err)
+
+					actual := c.expect.ProtoReflect().New().Interface()
+					require.NoError(t, proto.Unmarshal(out, actual))
+					assert.True(t, proto.Equal(c.expect, actual), "expected %s, got %s", c.expect, actual)
+				})
+			}
+		})
+	}
+}
+
+func testTransferType(t *testing.T) protoreflect.MessageDescriptor {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test/v1/transfer.proto"),
+		Package: proto.String("test.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Transfer"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("from"), JsonName: proto.String("from"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	return file.Messages().Get(0)
+}
+
+func testTransfer(t *testing.T, from string) []byte {
+	msg := dynamicpb.NewMessage(testTransferType(t))
+	msg.Set(msg.Descriptor().Fields().ByName("from"), protoreflect.ValueOfString(from))
+	value, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return value
+}
@@ -0,0 +1,37 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+func writeToHeap(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	stack := []uint64{uint64(len(data))}
+	if err := mod.ExportedFunction("alloc").CallWithStack(ctx, stack); err != nil {
+		return 0, fmt.Errorf("alloc from: %w", err)
+	}
+	ptr := uint32(stack[0])
+	if ok := mod.Memory().Write(ptr, data); !ok {
+		return 0, fmt.Errorf("could not write to memory")
+	}
+	return ptr, nil
+}
+
+func readBytesFromStack(mod api.Module, stack []uint64) []byte {
+	ptr, length := uint32(stack[0]), uint32(stack[1])
+	return readBytes(mod, ptr, length)
+}
+
+func readStringFromStack(mod api.Module, stack []uint64) string {
+	return string(readBytesFromStack(mod, stack))
+}
+
+func readBytes(mod api.Module, ptr, length uint32) []byte {
+	bytes, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		panic(fmt.Sprintf("could not read bytes, ptr=%d, len=%d", ptr, length))
+	}
+	return bytes
+}
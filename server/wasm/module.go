@@ -0,0 +1,106 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// A Module holds the wazero runtime with the pre-compiled `env` and `kv` host modules
+// as well as the pre-compiled WASM query module provided by the user. A fresh instance of the
+// user module is created for each call, so calls are isolated from each other and
+// can run concurrently.
+type Module struct {
+	sync.Mutex
+	wazRuntime  wazero.Runtime
+	hostModules []wazero.CompiledModule
+	userModule  wazero.CompiledModule
+}
+
+func NewModule(ctx context.Context, wasmCode []byte) (*Module, error) {
+	// wazero is pure Go, it compiles ahead of time on supported platforms and
+	// falls back to its interpreter otherwise. Calls are aborted once their context is
+	// done, a module looping forever would otherwise hold its goroutine.
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+
+	envModule, err := addHostFunctions(ctx, runtime, "env", envFuncs)
+	if err != nil {
+		return nil, fmt.Errorf("compiling host module: %w", err)
+	}
+
+	kvModule, err := addHostFunctions(ctx, runtime, "kv", kvFuncs)
+	if err != nil {
+		return nil, fmt.Errorf("compiling host module: %w", err)
+	}
+
+	mod, err := runtime.CompileModule(ctx, wasmCode)
+	if err != nil {
+		return nil, fmt.Errorf("compiling wasm query module: %w", err)
+	}
+
+	if mod.ExportedFunctions()["alloc"] == nil {
+		return nil, fmt.Errorf("missing required functions: alloc")
+	}
+
+	return &Module{
+		wazRuntime:  runtime,
+		hostModules: []wazero.CompiledModule{envModule, kvModule},
+		userModule:  mod,
+	}, nil
+}
+
+// HasEntrypoint returns true if the user module exports a function with this name
+// accepting a pointer and a length to the request bytes.
+func (m *Module) HasEntrypoint(name string) bool {
+	f := m.userModule.ExportedFunctions()[name]
+	if f == nil {
+		return false
+	}
+
+	params := f.ParamTypes()
+	return len(params) == 2 && params[0] == api.ValueTypeI32 && params[1] == api.ValueTypeI32 && len(f.ResultTypes()) == 0
+}
+
+func (m *Module) Close(ctx context.Context) error {
+	return m.wazRuntime.Close(ctx)
+}
+
+func (m *Module) instantiate(ctx context.Context) (api.Module, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, hostMod := range m.hostModules {
+		if m.wazRuntime.Module(hostMod.Name()) != nil {
+			continue
+		}
+		if _, err := m.wazRuntime.InstantiateModule(ctx, hostMod, wazero.NewModuleConfig().WithName(hostMod.Name())); err != nil {
+			return nil, fmt.Errorf("instantiating host module %q: %w", hostMod.Name(), err)
+		}
+	}
+
+	// An empty name makes the instance anonymous so many of them can live at the same time
+	return m.wazRuntime.InstantiateModule(ctx, m.userModule, wazero.NewModuleConfig().WithName(""))
+}
+
+type funcs struct {
+	name   string
+	input  []api.ValueType
+	output []api.ValueType
+	f      api.GoModuleFunction
+}
+
+var i32 = api.ValueTypeI32
+
+func addHostFunctions(ctx context.Context, runtime wazero.Runtime, moduleName string, funcs []funcs) (wazero.CompiledModule, error) {
+	build := runtime.NewHostModuleBuilder(moduleName)
+	for _, f := range funcs {
+		build.NewFunctionBuilder().
+			WithGoModuleFunction(f.f, f.input, f.output).
+			WithName(f.name).
+			Export(f.name)
+	}
+	return build.Compile(ctx)
+}
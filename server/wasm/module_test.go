@@ -0,0 +1,106 @@
+package wasm
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func newTestModule(t *testing.T, file string) *Module {
+	t.Helper()
+
+	code, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	module, err := NewModule(context.Background(), code)
+	require.NoError(t, err)
+	t.Cleanup(func() { module.Close(context.Background()) })
+
+	return module
+}
+
+func TestModule_HasEntrypoint(t *testing.T) {
+	module := newTestModule(t, "testdata/echo.wasm")
+
+	assert.True(t, module.HasEntrypoint("Echo"))
+	assert.True(t, module.HasEntrypoint("Trap"))
+	assert.True(t, module.HasEntrypoint("Spin"))
+	assert.False(t, module.HasEntrypoint("alloc"))
+	assert.False(t, module.HasEntrypoint("Missing"))
+}
+
+func TestModule_Execute(t *testing.T) {
+	module := newTestModule(t, "testdata/echo.wasm")
+
+	tests := []struct {
+		name          string
+		entrypoint    string
+		request       []byte
+		expect        []byte
+		expectErrCode connect.Code
+		expectErr     bool
+	}{
+		{
+			name:       "output",
+			entrypoint: "Echo",
+			request:    []byte("hello"),
+			expect:     []byte("hello"),
+		},
+		{
+			name:          "set error",
+			entrypoint:    "Echo",
+			request:       []byte{},
+			expectErrCode: connect.CodeInvalidArgument,
+			expectErr:     true,
+		},
+		{
+			name:       "trap",
+			entrypoint: "Trap",
+			request:    []byte("hello"),
+			expectErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := module.Execute(context.Background(), &Call{Entrypoint: test.entrypoint}, test.request)
+			if !test.expectErr {
+				require.NoError(t, err)
+				assert.Equal(t, test.expect, out)
+				return
+			}
+
+			require.Error(t, err)
+			if test.expectErrCode != 0 {
+				assert.Equal(t, test.expectErrCode, connect.CodeOf(err))
+				assert.Equal(t, "empty request", err.(*connect.Error).Message())
+			}
+		})
+	}
+}
+
+func TestModule_ExecuteContextDone(t *testing.T) {
+	module := newTestModule(t, "testdata/echo.wasm")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := module.Execute(ctx, &Call{Entrypoint: "Spin"}, []byte("hello"))
+	require.Error(t, err)
+	assert.Equal(t, connect.CodeDeadlineExceeded, connect.CodeOf(err))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = module.Execute(ctx, &Call{Entrypoint: "Spin"}, []byte("hello"))
+	require.Error(t, err)
+	assert.Equal(t, connect.CodeCanceled, connect.CodeOf(err))
+
+	// the module still serves calls once a spinning one was aborted
+	out, err := module.Execute(context.Background(), &Call{Entrypoint: "Echo"}, []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), out)
+}
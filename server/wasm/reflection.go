@@ -0,0 +1,86 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"connectrpc.com/connect"
+	connectweb "github.com/streamingfast/dgrpc/server/connect-web"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/reflect/protodesc"
+)
+
+// reflectionHandlers serve the gRPC reflection services, v1 and v1alpha, listing the
+// WASM query service and resolving descriptors against the spkg proto files. The dgrpc
+// reflection only knows the global registry, where the spkg files are not registered.
+func reflectionHandlers(grpcService string, files protodesc.Resolver, types *typeResolver) []connectweb.HandlerGetter {
+	opts := reflection.ServerOptions{
+		Services:           reflectedServices{grpcService},
+		DescriptorResolver: files,
+		ExtensionResolver:  types,
+	}
+	v1 := reflection.NewServerV1(opts)
+	v1alpha := reflection.NewServer(opts)
+
+	return []connectweb.HandlerGetter{
+		func(handlerOpts ...connect.HandlerOption) (string, http.Handler) {
+			return "/grpc.reflection.v1.ServerReflection/", connect.NewBidiStreamHandler(
+				reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName,
+				func(ctx context.Context, stream *connect.BidiStream[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse]) error {
+					return v1.ServerReflectionInfo(&reflectionStream[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse]{ctx: ctx, stream: stream})
+				},
+				handlerOpts...,
+			)
+		},
+		func(handlerOpts ...connect.HandlerOption) (string, http.Handler) {
+			return "/grpc.reflection.v1alpha.ServerReflection/", connect.NewBidiStreamHandler(
+				reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName,
+				func(ctx context.Context, stream *connect.BidiStream[reflectionv1alpha.ServerReflectionRequest, reflectionv1alpha.ServerReflectionResponse]) error {
+					return v1alpha.ServerReflectionInfo(&reflectionStream[reflectionv1alpha.ServerReflectionRequest, reflectionv1alpha.ServerReflectionResponse]{ctx: ctx, stream: stream})
+				},
+				handlerOpts...,
+			)
+		},
+	}
+}
+
+type reflectedServices []string
+
+func (s reflectedServices) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := make(map[string]grpc.ServiceInfo, len(s))
+	for _, service := range s {
+		info[service] = grpc.ServiceInfo{}
+	}
+	return info
+}
+
+// reflectionStream adapts a Connect stream to the gRPC one expected by the reflection
+// server, which only sends, receives and reads the context.
+type reflectionStream[Req, Res any] struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	stream *connect.BidiStream[Req, Res]
+}
+
+func (s *reflectionStream[Req, Res]) Context() context.Context {
+	return s.ctx
+}
+
+func (s *reflectionStream[Req, Res]) Send(response *Res) error {
+	return s.stream.Send(response)
+}
+
+func (s *reflectionStream[Req, Res]) Recv() (*Req, error) {
+	request, err := s.stream.Receive()
+	if errors.Is(err, io.EOF) {
+		// the reflection server only ends the stream on io.EOF itself
+		return nil, io.EOF
+	}
+	return request, err
+}
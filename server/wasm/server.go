@@ -0,0 +1,186 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/streamingfast/dgrpc/server"
+	connectweb "github.com/streamingfast/dgrpc/server/connect-web"
+	"github.com/streamingfast/substreams-sink-kv/db"
+	kvv1 "github.com/streamingfast/substreams-sink-kv/pb/substreams/sink/kv/v1"
+	"github.com/streamingfast/substreams-sink-kv/protofiles"
+	sserver "github.com/streamingfast/substreams-sink-kv/server"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var _ sserver.Serveable = (*Server)(nil)
+
+// Server exposes the user defined `grpc_service` of a WASMQueryService over Connect,
+// each unary method of the service being served by the WASM query module export of
+// the same name. Handlers are built at runtime from the spkg proto files, requests and
+// responses are decoded with `dynamicpb` so every protocol supported by Connect is.
+type Server struct {
+	srv      *connectweb.ConnectWebServer
+	module   *Module
+	types    *typeResolver
+	DBReader db.Reader
+	logger   *zap.Logger
+}
+
+func NewServer(ctx context.Context, config *kvv1.WASMQueryService, protoFiles []*descriptorpb.FileDescriptorProto, dbReader db.Reader, logger *zap.Logger, encrypted bool) (*Server, error) {
+	files, err := protofiles.New(protoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("loading spkg proto files: %w", err)
+	}
+
+	service, err := findService(files, config.GrpcService)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := NewModule(ctx, config.WasmQueryModule)
+	if err != nil {
+		return nil, fmt.Errorf("loading wasm query module: %w", err)
+	}
+
+	types, err := newTypes(files)
+	if err != nil {
+		return nil, fmt.Errorf("loading spkg proto types: %w", err)
+	}
+
+	s := &Server{
+		module:   module,
+		types:    types,
+		DBReader: dbReader,
+		logger:   logger,
+	}
+
+	if err := s.checkMethods(service); err != nil {
+		return nil, err
+	}
+
+	handlerGetter := func(opts ...connect.HandlerOption) (string, http.Handler) {
+		return fmt.Sprintf("/%s/", config.GrpcService), s.newMux(service, opts...)
+	}
+	handlerGetters := append([]connectweb.HandlerGetter{handlerGetter}, reflectionHandlers(config.GrpcService, protofiles.NewResolver(files), types)...)
+
+	opts := []server.Option{
+		server.WithLogger(logger),
+		server.WithPermissiveCORS(),
+		server.WithHealthCheck(server.HealthCheckOverHTTP, func(_ context.Context) (isReady bool, out interface{}, err error) { return true, nil, nil }),
+	}
+
+	if encrypted {
+		opts = append(opts, server.WithInsecureServer())
+	} else {
+		opts = append(opts, server.WithPlainTextServer())
+	}
+	s.srv = connectweb.New(handlerGetters, opts...)
+	return s, nil
+}
+
+func (s *Server) Shutdown() {
+	s.logger.Info("wasm query server received shutdown, shutting down server")
+	s.srv.Shutdown(nil)
+	if err := s.module.Close(context.Background()); err != nil {
+		s.logger.Warn("unable to close wasm query module", zap.Error(err))
+	}
+}
+
+func (s *Server) Serve(listenAddr string) error {
+	go s.srv.Launch(listenAddr)
+	<-s.srv.Terminated()
+
+	return s.srv.Err()
+}
+
+// checkMethods ensures each method of the service can be served by newMux.
+func (s *Server) checkMethods(service protoreflect.ServiceDescriptor) error {
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			return fmt.Errorf("method %s/%s: only unary methods are supported", service.FullName(), method.Name())
+		}
+		if !s.module.HasEntrypoint(string(method.Name())) {
+			return fmt.Errorf("method %s/%s: wasm query module does not export a %q function accepting a pointer and a length", service.FullName(), method.Name(), method.Name())
+		}
+	}
+	return nil
+}
+
+// newMux routes each method of the service to its WASM query module entrypoint, the
+// methods being validated by checkMethods. The options are the ones of the server,
+// interceptors included.
+func (s *Server) newMux(service protoreflect.ServiceDescriptor, opts ...connect.HandlerOption) *http.ServeMux {
+	mux := http.NewServeMux()
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		procedure := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+		mux.Handle(procedure, connect.NewUnaryHandler(
+			procedure,
+			s.unaryHandler(method),
+			append(append([]connect.HandlerOption{
+				connect.WithSchema(method),
+				connect.WithRequestInitializer(initializeRequest),
+			}, codecs(s.types)...), opts...)...,
+		))
+	}
+	return mux
+}
+
+func (s *Server) unaryHandler(method protoreflect.MethodDescriptor) func(context.Context, *connect.Request[dynamicpb.Message]) (*connect.Response[dynamicpb.Message], error) {
+	entrypoint := string(method.Name())
+
+	return func(ctx context.Context, req *connect.Request[dynamicpb.Message]) (*connect.Response[dynamicpb.Message], error) {
+		logger := s.logger.With(zap.String("entrypoint", entrypoint))
+
+		request, err := proto.Marshal(req.Msg)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid request: %w", err))
+		}
+
+		out, err := s.module.Execute(ctx, &Call{Entrypoint: entrypoint, DBReader: s.DBReader, Logger: logger}, request)
+		if err != nil {
+			var connectErr *connect.Error
+			if errors.As(err, &connectErr) {
+				logger.Debug("wasm query module returned an error", zap.Error(err))
+				return nil, connectErr
+			}
+			logger.Info("internal error", zap.Error(err))
+			return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+		}
+
+		resp := dynamicpb.NewMessage(method.Output())
+		if err := (proto.UnmarshalOptions{Resolver: s.types}).Unmarshal(out, resp); err != nil {
+			logger.Info("wasm query module returned an invalid response", zap.String("message", string(method.Output().FullName())), zap.Error(err))
+			return nil, connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+		}
+
+		return connect.NewResponse(resp), nil
+	}
+}
+
+// initializeRequest sets the request message type from the method schema, connect
+// would otherwise decode into an empty `dynamicpb.Message`.
+func initializeRequest(spec connect.Spec, message any) error {
+	method, ok := spec.Schema.(protoreflect.MethodDescriptor)
+	if !ok {
+		return fmt.Errorf("unexpected schema type %T", spec.Schema)
+	}
+
+	msg, ok := message.(*dynamicpb.Message)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", message)
+	}
+	*msg = *dynamicpb.NewMessage(method.Input())
+	return nil
+}
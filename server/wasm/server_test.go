@@ -0,0 +1,242 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/streamingfast/substreams-sink-kv/protofiles"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+	"go.uber.org/zap"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func echoProtoFile(methods ...string) *descriptorpb.FileDescriptorProto {
+	message := func(name string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("text"), JsonName: proto.String("text"), Number: proto.Int32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("at"), JsonName: proto.String("at"), Number: proto.Int32(2), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".google.protobuf.Timestamp")},
+			},
+		}
+	}
+
+	service := &descriptorpb.ServiceDescriptorProto{Name: proto.String("Echo")}
+	for _, method := range methods {
+		service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(method),
+			InputType:  proto.String(".test.v1.EchoRequest"),
+			OutputType: proto.String(".test.v1.EchoResponse"),
+		})
+	}
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("test/v1/echo.proto"),
+		Package:     proto.String("test.v1"),
+		Syntax:      proto.String("proto3"),
+		Dependency:  []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{message("EchoRequest"), message("EchoResponse")},
+		Service:     []*descriptorpb.ServiceDescriptorProto{service},
+	}
+}
+
+func TestServer_FindService(t *testing.T) {
+	files, err := protofiles.New([]*descriptorpb.FileDescriptorProto{echoProtoFile("Echo")})
+	require.NoError(t, err)
+
+	service, err := findService(files, "test.v1.Echo")
+	require.NoError(t, err)
+	assert.Equal(t, "Echo", string(service.Methods().Get(0).Name()))
+
+	_, err = findService(files, "test.v1.Missing")
+	require.Error(t, err)
+
+	_, err = findService(files, "test.v1.EchoRequest")
+	require.Error(t, err)
+}
+
+func TestServer_DynamicHandler(t *testing.T) {
+	files, err := protofiles.New([]*descriptorpb.FileDescriptorProto{echoProtoFile("Echo", "Trap")})
+	require.NoError(t, err)
+	service, err := findService(files, "test.v1.Echo")
+	require.NoError(t, err)
+
+	types, err := newTypes(files)
+	require.NoError(t, err)
+
+	s := &Server{module: newTestModule(t, "testdata/echo.wasm"), types: types, logger: zap.NewNop()}
+	require.NoError(t, s.checkMethods(service))
+
+	srv := httptest.NewServer(s.newMux(service))
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		procedure    string
+		body         string
+		expectStatus int
+		expectBody   map[string]interface{}
+	}{
+		{
+			name:         "json",
+			procedure:    "/test.v1.Echo/Echo",
+			body:         `{"text":"hello","at":"2023-01-01T00:00:00Z"}`,
+			expectStatus: http.StatusOK,
+			expectBody:   map[string]interface{}{"text": "hello", "at": "2023-01-01T00:00:00Z"},
+		},
+		{
+			name:         "unknown field",
+			procedure:    "/test.v1.Echo/Echo",
+			body:         `{"unknown":"hello"}`,
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "module error",
+			procedure:    "/test.v1.Echo/Echo",
+			body:         `{}`,
+			expectStatus: http.StatusBadRequest,
+			expectBody:   map[string]interface{}{"code": "invalid_argument", "message": "empty request"},
+		},
+		{
+			name:         "module trap",
+			procedure:    "/test.v1.Echo/Trap",
+			body:         `{"text":"hello"}`,
+			expectStatus: http.StatusInternalServerError,
+			expectBody:   map[string]interface{}{"code": "internal", "message": "internal server error"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+test.procedure, "application/json", strings.NewReader(test.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectStatus, resp.StatusCode)
+			if test.expectBody == nil {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			actual := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(body, &actual))
+			assert.Equal(t, test.expectBody, actual)
+		})
+	}
+}
+
+func TestServer_MissingEntrypoint(t *testing.T) {
+	files, err := protofiles.New([]*descriptorpb.FileDescriptorProto{echoProtoFile("Echo", "Missing")})
+	require.NoError(t, err)
+	service, err := findService(files, "test.v1.Echo")
+	require.NoError(t, err)
+
+	s := &Server{module: newTestModule(t, "testdata/echo.wasm"), logger: zap.NewNop()}
+	require.Error(t, s.checkMethods(service))
+}
+
+func TestServer_HandlerOptions(t *testing.T) {
+	files, err := protofiles.New([]*descriptorpb.FileDescriptorProto{echoProtoFile("Echo")})
+	require.NoError(t, err)
+	service, err := findService(files, "test.v1.Echo")
+	require.NoError(t, err)
+
+	types, err := newTypes(files)
+	require.NoError(t, err)
+
+	s := &Server{module: newTestModule(t, "testdata/echo.wasm"), types: types, logger: zap.NewNop()}
+	denyAll := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return nil, connect.NewError(connect.CodePermissionDenied, errors.New("denied"))
+		}
+	})
+
+	srv := httptest.NewServer(s.newMux(service, connect.WithInterceptors(denyAll)))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/test.v1.Echo/Echo", "application/json", strings.NewReader(`{"text":"hello"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestServer_Types(t *testing.T) {
+	files, err := protofiles.New([]*descriptorpb.FileDescriptorProto{echoProtoFile("Echo")})
+	require.NoError(t, err)
+
+	types, err := newTypes(files)
+	require.NoError(t, err)
+
+	messageType, err := types.FindMessageByURL("type.googleapis.com/test.v1.EchoRequest")
+	require.NoError(t, err)
+	assert.Equal(t, "test.v1.EchoRequest", string(messageType.Descriptor().FullName()))
+
+	// imports are resolved against the types linked in this binary
+	_, err = types.FindMessageByName("google.protobuf.Timestamp")
+	require.NoError(t, err)
+
+	// the spkg files are kept out of the global registries
+	_, err = protoregistry.GlobalFiles.FindDescriptorByName("test.v1.Echo")
+	assert.Equal(t, protoregistry.NotFound, err)
+	_, err = protoregistry.GlobalTypes.FindMessageByName("test.v1.EchoRequest")
+	assert.Equal(t, protoregistry.NotFound, err)
+}
+
+func TestServer_Reflection(t *testing.T) {
+	files, err := protofiles.New([]*descriptorpb.FileDescriptorProto{echoProtoFile("Echo")})
+	require.NoError(t, err)
+	types, err := newTypes(files)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	for _, handlerGetter := range reflectionHandlers("test.v1.Echo", protofiles.NewResolver(files), types) {
+		mux.Handle(handlerGetter())
+	}
+	srv := httptest.NewUnstartedServer(mux)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	client := connect.NewClient[reflectionv1.ServerReflectionRequest, reflectionv1.ServerReflectionResponse](srv.Client(), srv.URL+reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName, connect.WithGRPC())
+	stream := client.CallBidiStream(context.Background())
+
+	require.NoError(t, stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Receive()
+	require.NoError(t, err)
+	require.Len(t, resp.GetListServicesResponse().GetService(), 1)
+	assert.Equal(t, "test.v1.Echo", resp.GetListServicesResponse().GetService()[0].GetName())
+
+	require.NoError(t, stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "test.v1.Echo"},
+	}))
+	resp, err = stream.Receive()
+	require.NoError(t, err)
+
+	var paths []string
+	for _, encoded := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		require.NoError(t, proto.Unmarshal(encoded, file))
+		paths = append(paths, file.GetName())
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{"google/protobuf/timestamp.proto", "test/v1/echo.proto"}, paths)
+
+	require.NoError(t, stream.CloseRequest())
+	require.NoError(t, stream.CloseResponse())
+}
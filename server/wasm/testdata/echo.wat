@@ -0,0 +1,32 @@
+;; Minimal WASM query module used by the tests, compile with any wat2wasm tool:
+;;
+;;   wat2wasm echo.wat -o echo.wasm
+(module
+  (import "env" "output" (func $output (param i32 i32)))
+  (import "env" "set_error" (func $set_error (param i32 i32 i32)))
+
+  (memory 1)
+  (export "memory" (memory 0))
+  (global $heap (mut i32) (i32.const 1024))
+  (data (i32.const 0) "empty request")
+
+  ;; bump allocator, memory is never reclaimed since instances are short lived
+  (func (export "alloc") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $heap))
+    (global.set $heap (i32.add (global.get $heap) (local.get $size)))
+    (local.get $ptr))
+
+  ;; Echo outputs the request as is, an empty request is an INVALID_ARGUMENT (3) error
+  (func (export "Echo") (param $ptr i32) (param $len i32)
+    (if (i32.eqz (local.get $len))
+      (then (call $set_error (i32.const 3) (i32.const 0) (i32.const 13)))
+      (else (call $output (local.get $ptr) (local.get $len)))))
+
+  (func (export "Trap") (param $ptr i32) (param $len i32)
+    unreachable)
+
+  ;; Spin never returns, the call only ends when its context is done
+  (func (export "Spin") (param $ptr i32) (param $len i32)
+    (loop $spin (br $spin)))
+)
@@ -0,0 +1,68 @@
+;; WASM query module forwarding each `Kv` method to the `kv` host module, used by the
+;; tests to cover the host functions ABI. Compile with any wat2wasm tool:
+;;
+;;   wat2wasm kv.wat -o kv.wasm
+(module
+  (import "env" "output" (func $output (param i32 i32)))
+  (import "env" "set_error" (func $set_error (param i32 i32 i32)))
+  (import "kv" "get" (func $get (param i32 i32 i32) (result i32)))
+  (import "kv" "get_many" (func $get_many (param i32 i32 i32) (result i32)))
+  (import "kv" "get_by_prefix" (func $get_by_prefix (param i32 i32 i32) (result i32)))
+  (import "kv" "scan" (func $scan (param i32 i32 i32) (result i32)))
+  (import "kv" "query_index" (func $query_index (param i32 i32 i32) (result i32)))
+  (import "kv" "count" (func $count (param i32 i32 i32) (result i32)))
+
+  ;; the host writes the output pointer and length at address 0
+  (memory 1)
+  (export "memory" (memory 0))
+  (global $heap (mut i32) (i32.const 1024))
+
+  ;; bump allocator, memory is never reclaimed since instances are short lived
+  (func (export "alloc") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $heap))
+    (global.set $heap (i32.add (global.get $heap) (local.get $size)))
+    (local.get $ptr))
+
+  (func (export "Get") (param $ptr i32) (param $len i32)
+    (local $status i32)
+    (local.set $status (call $get (local.get $ptr) (local.get $len) (i32.const 0)))
+    (if (local.get $status)
+      (then (call $set_error (local.get $status) (i32.load (i32.const 0)) (i32.load (i32.const 4))))
+      (else (call $output (i32.load (i32.const 0)) (i32.load (i32.const 4))))))
+
+  (func (export "GetMany") (param $ptr i32) (param $len i32)
+    (local $status i32)
+    (local.set $status (call $get_many (local.get $ptr) (local.get $len) (i32.const 0)))
+    (if (local.get $status)
+      (then (call $set_error (local.get $status) (i32.load (i32.const 0)) (i32.load (i32.const 4))))
+      (else (call $output (i32.load (i32.const 0)) (i32.load (i32.const 4))))))
+
+  (func (export "GetByPrefix") (param $ptr i32) (param $len i32)
+    (local $status i32)
+    (local.set $status (call $get_by_prefix (local.get $ptr) (local.get $len) (i32.const 0)))
+    (if (local.get $status)
+      (then (call $set_error (local.get $status) (i32.load (i32.const 0)) (i32.load (i32.const 4))))
+      (else (call $output (i32.load (i32.const 0)) (i32.load (i32.const 4))))))
+
+  (func (export "Scan") (param $ptr i32) (param $len i32)
+    (local $status i32)
+    (local.set $status (call $scan (local.get $ptr) (local.get $len) (i32.const 0)))
+    (if (local.get $status)
+      (then (call $set_error (local.get $status) (i32.load (i32.const 0)) (i32.load (i32.const 4))))
+      (else (call $output (i32.load (i32.const 0)) (i32.load (i32.const 4))))))
+
+  (func (export "QueryIndex") (param $ptr i32) (param $len i32)
+    (local $status i32)
+    (local.set $status (call $query_index (local.get $ptr) (local.get $len) (i32.const 0)))
+    (if (local.get $status)
+      (then (call $set_error (local.get $status) (i32.load (i32.const 0)) (i32.load (i32.const 4))))
+      (else (call $output (i32.load (i32.const 0)) (i32.load (i32.const 4))))))
+
+  (func (export "Count") (param $ptr i32) (param $len i32)
+    (local $status i32)
+    (local.set $status (call $count (local.get $ptr) (local.get $len) (i32.const 0)))
+    (if (local.get $status)
+      (then (call $set_error (local.get $status) (i32.load (i32.const 0)) (i32.load (i32.const 4))))
+      (else (call $output (i32.load (i32.const 0)) (i32.load (i32.const 4))))))
+)
@@ -0,0 +1,123 @@
+//! Rust WASM query module forwarding each `Kv` method to the `kv` host module, it is
+//! the Rust counterpart of `kv.wat`. The built `kv_guest.wasm` is committed, it is
+//! rebuilt with `go generate ./server/wasm/...`, which runs:
+//!
+//!   cargo build --target wasm32-unknown-unknown --release
+//!   cp target/wasm32-unknown-unknown/release/kv_guest.wasm ..
+//!
+//! The module is `no_std`, instances only live for one call so memory is handed out by
+//! a bump allocator and never reclaimed.
+
+#![no_std]
+#![allow(non_snake_case)]
+
+use core::arch::wasm32;
+
+mod env {
+    #[link(wasm_import_module = "env")]
+    extern "C" {
+        pub fn output(ptr: *const u8, len: u32);
+        pub fn set_error(code: u32, ptr: *const u8, len: u32);
+    }
+}
+
+mod kv {
+    #[link(wasm_import_module = "kv")]
+    extern "C" {
+        pub fn get(ptr: *const u8, len: u32, output_ptr: *mut u32) -> u32;
+        pub fn get_many(ptr: *const u8, len: u32, output_ptr: *mut u32) -> u32;
+        pub fn get_by_prefix(ptr: *const u8, len: u32, output_ptr: *mut u32) -> u32;
+        pub fn scan(ptr: *const u8, len: u32, output_ptr: *mut u32) -> u32;
+        pub fn query_index(ptr: *const u8, len: u32, output_ptr: *mut u32) -> u32;
+        pub fn count(ptr: *const u8, len: u32, output_ptr: *mut u32) -> u32;
+    }
+}
+
+#[panic_handler]
+fn panic(_info: &core::panic::PanicInfo) -> ! {
+    wasm32::unreachable()
+}
+
+const PAGE_SIZE: usize = 65536;
+
+extern "C" {
+    /// First address after the static data and the stack, set by the linker.
+    static __heap_base: u8;
+}
+
+/// Next free address of the heap, 0 until the first allocation.
+static mut HEAP_NEXT: usize = 0;
+
+/// Memory handed out to the host is owned by the host for the rest of the call.
+#[no_mangle]
+pub extern "C" fn alloc(size: u32) -> *mut u8 {
+    unsafe {
+        if HEAP_NEXT == 0 {
+            HEAP_NEXT = &raw const __heap_base as usize;
+        }
+        let ptr = HEAP_NEXT;
+        HEAP_NEXT = ptr + size as usize;
+
+        let memory_end = wasm32::memory_size(0) * PAGE_SIZE;
+        if HEAP_NEXT > memory_end {
+            let pages = (HEAP_NEXT - memory_end + PAGE_SIZE - 1) / PAGE_SIZE;
+            if wasm32::memory_grow(0, pages) == usize::MAX {
+                wasm32::unreachable()
+            }
+        }
+        ptr as *mut u8
+    }
+}
+
+type HostFn = unsafe extern "C" fn(*const u8, u32, *mut u32) -> u32;
+
+/// Calls the host function with the request and forwards its output as the response,
+/// or its status and message as the error of the call.
+fn forward(host_fn: HostFn, ptr: *const u8, len: u32) {
+    let mut output = Output { ptr: 0, len: 0 };
+    unsafe {
+        let status = host_fn(ptr, len, &raw mut output as *mut u32);
+        if status == 0 {
+            env::output(output.ptr as *const u8, output.len);
+        } else {
+            env::set_error(status, output.ptr as *const u8, output.len);
+        }
+    }
+}
+
+/// Pointer and length of the output of a host function, written by the host.
+#[repr(C)]
+struct Output {
+    ptr: u32,
+    len: u32,
+}
+
+#[no_mangle]
+pub extern "C" fn Get(ptr: *const u8, len: u32) {
+    forward(kv::get, ptr, len)
+}
+
+#[no_mangle]
+pub extern "C" fn GetMany(ptr: *const u8, len: u32) {
+    forward(kv::get_many, ptr, len)
+}
+
+#[no_mangle]
+pub extern "C" fn GetByPrefix(ptr: *const u8, len: u32) {
+    forward(kv::get_by_prefix, ptr, len)
+}
+
+#[no_mangle]
+pub extern "C" fn Scan(ptr: *const u8, len: u32) {
+    forward(kv::scan, ptr, len)
+}
+
+#[no_mangle]
+pub extern "C" fn QueryIndex(ptr: *const u8, len: u32) {
+    forward(kv::query_index, ptr, len)
+}
+
+#[no_mangle]
+pub extern "C" fn Count(ptr: *const u8, len: u32) {
+    forward(kv::count, ptr, len)
+}
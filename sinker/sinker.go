@@ -30,8 +30,7 @@ type KVSinker struct {
 	logger        *zap.Logger
 	tracer        logging.Tracer
 
-	lastCursor *sink.Cursor
-	stats      *Stats
+	stats *Stats
 }
 
 func New(sinker *sink.Sinker, dbLoader *db.OperationDB, flushInterval uint64, logger *zap.Logger, tracer logging.Tracer) (*KVSinker, error) {
@@ -46,16 +45,15 @@ func New(sinker *sink.Sinker, dbLoader *db.OperationDB, flushInterval uint64, lo
 		stats: NewStats(logger),
 	}
 
-	s.OnTerminating(func(err error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		s.onTerminating(ctx, err)
-	})
-
 	return s, nil
 }
 
 func (s *KVSinker) Run(ctx context.Context) {
+	if err := s.operationDB.RecoverPendingFlush(ctx); err != nil {
+		s.Shutdown(fmt.Errorf("unable to recover interrupted flush: %w", err))
+		return
+	}
+
 	cursor, err := s.operationDB.GetCursor(ctx)
 	if err != nil && !errors.Is(err, db.ErrCursorNotFound) {
 		s.Shutdown(fmt.Errorf("unable to retrieve cursor: %w", err))
@@ -82,14 +80,6 @@ func (s *KVSinker) Run(ctx context.Context) {
 	s.Sinker.Run(ctx, cursor, sink.NewSinkerHandlers(s.handleBlockScopedData, s.handleBlockUndoSignal))
 }
 
-func (s *KVSinker) onTerminating(ctx context.Context, err error) {
-	if s.lastCursor == nil || err != nil {
-		return
-	}
-
-	_ = s.operationDB.WriteCursor(ctx, s.lastCursor)
-}
-
 var lastBlockCompletedAt = time.Now()
 
 func (s *KVSinker) handleBlockScopedData(ctx context.Context, data *pbsubstreamsrpc.BlockScopedData, isLive *bool, cursor *sink.Cursor) error {
@@ -102,7 +92,17 @@ func (s *KVSinker) handleBlockScopedData(ctx context.Context, data *pbsubstreams
 		return fmt.Errorf("unmarshal database changes: %w", err)
 	}
 
-	err = s.operationDB.HandleOperations(ctx, data.Clock.Number, data.FinalBlockHeight, cursor.Step, kvOps)
+	var blockTime time.Time
+	if timestamp := data.Clock.GetTimestamp(); timestamp != nil {
+		blockTime = timestamp.AsTime()
+	}
+	expiredOps, err := s.operationDB.ExpiredOperations(ctx, data.Clock.Number, blockTime)
+	if err != nil {
+		return fmt.Errorf("finding expired keys: %w", err)
+	}
+	kvOps.Operations = append(expiredOps, kvOps.Operations...)
+
+	err = s.operationDB.HandleOperations(ctx, bstream.NewBlockRef(data.Clock.Id, data.Clock.Number), data.FinalBlockHeight, cursor.Step, kvOps)
 	if err != nil {
 		return fmt.Errorf("handling operation: %w", err)
 	}
@@ -122,7 +122,6 @@ func (s *KVSinker) handleBlockScopedData(ctx context.Context, data *pbsubstreams
 	}
 
 	s.stats.RecordProcessDuration(time.Since(start))
-	s.lastCursor = cursor
 	lastBlockCompletedAt = time.Now()
 	return nil
 }